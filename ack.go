@@ -0,0 +1,105 @@
+package main
+
+import (
+	"container/heap"
+	"math"
+	"time"
+)
+
+// A LeaseToken identifies an item leased from an AckQueue.
+type LeaseToken uint64
+
+// An AckQueue hands out items for at-least-once processing. Lease removes the
+// highest-priority item but keeps it in flight, where further Leases cannot
+// see it, until the worker either Acks it, removing it for good, or Nacks it,
+// returning it to the queue. Leases that are neither, say because the worker
+// died, can be returned with ExpireLeases.
+//
+// Every outstanding lease holds its Item and a map entry with the lease time
+// until it is acked, nacked or expired, so leases that are never settled
+// leak.
+type AckQueue struct {
+	q      IntQueue
+	next   LeaseToken
+	leases map[LeaseToken]lease
+
+	// NackPenalty is subtracted from an item's priority each time it returns
+	// to the queue, so an item that keeps failing sinks below fresh work.
+	NackPenalty int
+}
+
+type lease struct {
+	item *Item
+	at   time.Time
+}
+
+func NewAckQueue(n int) *AckQueue {
+	return &AckQueue{q: NewIntQueue(n), leases: make(map[LeaseToken]lease)}
+}
+
+// Len returns the number of items waiting to be leased.
+func (a *AckQueue) Len() int { return a.q.Len() }
+
+// InFlight returns the number of outstanding leases.
+func (a *AckQueue) InFlight() int { return len(a.leases) }
+
+// PushItem adds item to the queue.
+func (a *AckQueue) PushItem(item *Item) { heap.Push(&a.q, item) }
+
+// Lease removes the highest-priority item and returns it with a token to Ack
+// or Nack it by, or reports false if no item is waiting.
+func (a *AckQueue) Lease() (*Item, LeaseToken, bool) {
+	item, ok := a.q.TryPop()
+	if !ok {
+		return nil, 0, false
+	}
+	a.next++
+	a.leases[a.next] = lease{item, time.Now()}
+	return item, a.next, true
+}
+
+// Ack marks the lease as done and forgets its item. It reports false if the
+// token is not outstanding, for example because the lease expired.
+func (a *AckQueue) Ack(token LeaseToken) bool {
+	if _, ok := a.leases[token]; !ok {
+		return false
+	}
+	delete(a.leases, token)
+	return true
+}
+
+// Nack returns the leased item to the queue, lowering its priority by
+// NackPenalty. It reports false if the token is not outstanding.
+func (a *AckQueue) Nack(token LeaseToken) bool {
+	l, ok := a.leases[token]
+	if !ok {
+		return false
+	}
+	delete(a.leases, token)
+	a.requeue(l.item)
+	return true
+}
+
+// ExpireLeases returns every item leased before deadline to the queue, as if
+// it had been nacked, and returns how many there were. Their tokens are no
+// longer outstanding. It scans every outstanding lease.
+func (a *AckQueue) ExpireLeases(deadline time.Time) int {
+	n := 0
+	for token, l := range a.leases {
+		if l.at.Before(deadline) {
+			delete(a.leases, token)
+			a.requeue(l.item)
+			n++
+		}
+	}
+	return n
+}
+
+func (a *AckQueue) requeue(item *Item) {
+	if p := a.NackPenalty; p > 0 && item.priority < math.MinInt+p {
+		item.priority = math.MinInt
+	} else {
+		item.priority -= p
+	}
+	heap.Push(&a.q, item)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"container/heap"
+	"time"
+)
+
+// An AgingQueue pops by effective priority, an item's priority plus the time
+// it has waited multiplied by an aging rate, so a low-priority item cannot be
+// starved forever by a steady stream of higher ones: after waiting long
+// enough it overtakes anything pushed later.
+//
+// Every item ages at the same rate, so the difference between two effective
+// priorities never changes as time passes. The queue therefore orders items
+// by priority - rate*pushTime, computed when they are pushed, and never needs
+// to reheapify, while popping exactly as if effective priorities were
+// recomputed at pop time.
+type AgingQueue struct {
+	h     agingHeap
+	rate  float64
+	epoch time.Time // Push times are measured from here to keep them small.
+}
+
+// NewAgingQueue returns a queue whose items gain rate priority per second of
+// waiting. It panics if rate is negative or NaN.
+func NewAgingQueue(rate float64) *AgingQueue {
+	if !(rate >= 0) {
+		panic("heap: aging rate must not be negative")
+	}
+	return &AgingQueue{rate: rate, epoch: time.Now()}
+}
+
+func (a *AgingQueue) Len() int { return a.h.Len() }
+
+// PushItem adds item to the queue, starting to age now.
+func (a *AgingQueue) PushItem(item *Item) { a.PushAt(item, time.Now()) }
+
+// PushAt adds item to the queue as if it had been pushed at the given time.
+func (a *AgingQueue) PushAt(item *Item, at time.Time) {
+	key := float64(item.priority) - a.rate*at.Sub(a.epoch).Seconds()
+	heap.Push(&a.h, agingEntry{item, key})
+}
+
+// Pop removes and returns the item with the highest effective priority, or
+// reports false if the queue is empty. The item's priority is left as it was
+// pushed.
+func (a *AgingQueue) Pop() (*Item, bool) {
+	if a.h.Len() == 0 {
+		return nil, false
+	}
+	return heap.Pop(&a.h).(agingEntry).item, true
+}
+
+type agingEntry struct {
+	item *Item
+	key  float64
+}
+
+// agingHeap implements heap.Interface for AgingQueue, keeping each item's
+// index field up to date.
+type agingHeap []agingEntry
+
+func (h agingHeap) Len() int { return len(h) }
+
+func (h agingHeap) Less(i, j int) bool { return h[i].key > h[j].key }
+
+func (h agingHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].item.index = i
+	h[j].item.index = j
+}
+
+func (h *agingHeap) Push(x interface{}) {
+	e := x.(agingEntry)
+	e.item.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *agingHeap) Pop() interface{} {
+	a := *h
+	n := len(a)
+	e := a[n-1]
+	e.item.index = -1 // for safety
+	a[n-1] = agingEntry{}
+	*h = a[0 : n-1]
+	return e
+}
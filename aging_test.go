@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgingQueueOldItemOvertakes(t *testing.T) {
+	a := NewAgingQueue(1) // one priority point per second of waiting
+	start := time.Now()
+	old := &Item{value: -1, priority: 0}
+	a.PushAt(old, start)
+	// A newer item of priority 10 pushed s seconds after old has effective
+	// priority 10-s relative to it, so old overtakes every one pushed more
+	// than 10 seconds later.
+	for s := 1; s <= 20; s++ {
+		a.PushAt(&Item{value: s, priority: 10}, start.Add(time.Duration(s)*time.Second))
+	}
+	var order []int
+	for {
+		item, ok := a.Pop()
+		if !ok {
+			break
+		}
+		if item == old && item.priority != 0 {
+			t.Fatalf("Pop changed the base priority to %d", item.priority)
+		}
+		order = append(order, item.value)
+	}
+	pos := -1
+	for i, v := range order {
+		if v == -1 {
+			pos = i
+		}
+	}
+	// Items 1-9 beat old, item 10 ties with it, items 11-20 lose to it.
+	if pos < 9 || pos > 10 {
+		t.Fatalf("old item popped at position %d: %v", pos, order)
+	}
+	for _, v := range order[11:] {
+		if v <= 10 {
+			t.Fatalf("item %d popped after the old item: %v", v, order)
+		}
+	}
+}
+
+func TestAgingQueueZeroRate(t *testing.T) {
+	a := NewAgingQueue(0)
+	start := time.Now()
+	a.PushAt(&Item{value: 1, priority: 1}, start)
+	a.PushAt(&Item{value: 2, priority: 5}, start.Add(time.Hour))
+	if item, _ := a.Pop(); item.value != 2 {
+		t.Fatalf("popped %v, want value 2", item)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import "container/heap"
+
+// An AuditedIntQueue is an IntQueue that checks, as it pops, that items are
+// served in priority order. It counts an inversion whenever a pop returns an
+// item with a higher priority than the previous pop returned, although the
+// item was already queued at that previous pop, so it should have come out
+// first. In a correct heap that never happens; it does if something changes
+// priorities or the backing array without going through the heap. The
+// auditing is opt-in: plain IntQueues pay nothing for it.
+//
+// The queue uses the Items' seq fields to record push order, so an item must
+// not be in an AuditedIntQueue and an OrderedIntQueue at once.
+type AuditedIntQueue struct {
+	q          IntQueue
+	seq        uint64 // The seq given to the next pushed item.
+	lastSeq    uint64 // The value of seq when the last pop happened.
+	last       int    // The priority of the last popped item.
+	popped     bool   // Whether last is set.
+	inversions int
+}
+
+func NewAuditedIntQueue(n int) *AuditedIntQueue {
+	return &AuditedIntQueue{q: NewIntQueue(n)}
+}
+
+func (a *AuditedIntQueue) Len() int { return a.q.Len() }
+
+// Inversions returns the number of inversions seen so far.
+func (a *AuditedIntQueue) Inversions() int { return a.inversions }
+
+// PushItem adds item to the queue.
+func (a *AuditedIntQueue) PushItem(item *Item) {
+	item.seq = a.seq
+	a.seq++
+	heap.Push(&a.q, item)
+}
+
+// PopItem removes and returns the highest-priority item, or reports false if
+// the queue is empty.
+func (a *AuditedIntQueue) PopItem() (*Item, bool) {
+	item, ok := a.q.TryPop()
+	if !ok {
+		return nil, false
+	}
+	if a.popped && item.seq < a.lastSeq && item.priority > a.last {
+		a.inversions++
+	}
+	a.last, a.lastSeq, a.popped = item.priority, a.seq, true
+	return item, true
+}
+
+// Peek returns the highest-priority item without removing it, or reports
+// false if the queue is empty.
+func (a *AuditedIntQueue) Peek() (*Item, bool) { return a.q.PeekOK() }
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"container/heap"
+	"math/big"
+)
+
+// A BigItem is something we manage in a BigIntQueue: an Item whose priority
+// is arbitrarily large.
+type BigItem struct {
+	value    int      // The value of the item; arbitrary.
+	priority *big.Int // The priority of the item in the queue; never modified.
+	index    int      // The index of the item in the heap.
+}
+
+// A BigIntQueue implements heap.Interface and holds BigItems, popping the
+// highest priority first like IntQueue. A nil priority is treated as lower
+// than every other priority.
+type BigIntQueue []*BigItem
+
+func NewBigIntQueue(n int) BigIntQueue {
+	return make(BigIntQueue, 0, n)
+}
+
+func (pq BigIntQueue) Len() int { return len(pq) }
+
+func (pq BigIntQueue) Less(i, j int) bool {
+	a, b := pq[i].priority, pq[j].priority
+	switch {
+	case a == nil:
+		return false
+	case b == nil:
+		return true
+	}
+	return a.Cmp(b) > 0
+}
+
+func (pq BigIntQueue) Swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+	pq[i].index = i
+	pq[j].index = j
+}
+
+func (pq *BigIntQueue) Push(x interface{}) {
+	item := x.(*BigItem)
+	item.index = len(*pq)
+	*pq = append(*pq, item)
+}
+
+func (pq *BigIntQueue) Pop() interface{} {
+	a := *pq
+	n := len(a)
+	item := a[n-1]
+	item.index = -1 // for safety
+	*pq = a[0 : n-1]
+	return item
+}
+
+// PushItem adds an item with the given value and priority and returns it.
+// The queue keeps priority rather than a copy, so the caller must not modify
+// it afterwards.
+func (pq *BigIntQueue) PushItem(value int, priority *big.Int) *BigItem {
+	item := &BigItem{value: value, priority: priority}
+	heap.Push(pq, item)
+	return item
+}
+
+// PopItem removes and returns the highest-priority item, or reports false if
+// the queue is empty.
+func (pq *BigIntQueue) PopItem() (*BigItem, bool) {
+	if len(*pq) == 0 {
+		return nil, false
+	}
+	return heap.Pop(pq).(*BigItem), true
+}
+
+// Peek returns the highest-priority item without removing it, or reports
+// false if the queue is empty.
+func (pq BigIntQueue) Peek() (*BigItem, bool) {
+	if len(pq) == 0 {
+		return nil, false
+	}
+	return pq[0], true
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+)
+
+func TestBigIntQueueOrderAbove64Bits(t *testing.T) {
+	two64 := new(big.Int).Lsh(big.NewInt(1), 64)
+	priorities := []*big.Int{
+		new(big.Int).Add(two64, big.NewInt(1)), // value 0: 2^64 + 1
+		new(big.Int).Lsh(two64, 10),            // value 1: 2^74
+		two64,                                  // value 2: 2^64
+		big.NewInt(-1),                         // value 3
+		nil,                                    // value 4: lowest
+		new(big.Int).Neg(new(big.Int).Lsh(two64, 1)), // value 5: -2^65
+		new(big.Int).Sub(two64, big.NewInt(1)),       // value 6: 2^64 - 1
+	}
+	pq := NewBigIntQueue(0)
+	for v, p := range priorities {
+		pq.PushItem(v, p)
+	}
+	if top, ok := pq.Peek(); !ok || top.value != 1 {
+		t.Fatalf("Peek() = %v, %v", top, ok)
+	}
+	var got []int
+	for {
+		item, ok := pq.PopItem()
+		if !ok {
+			break
+		}
+		got = append(got, item.value)
+	}
+	if want := []int{1, 0, 2, 6, 3, 5, 4}; !slices.Equal(got, want) {
+		t.Fatalf("popped values %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"cmp"
+	"container/heap"
+	"errors"
+	"slices"
+)
+
+// ErrQueueFull is returned by CappedIntQueue.TryPush when the queue is full.
+var ErrQueueFull = errors.New("heap: queue is full")
+
+// A BoundedIntQueue holds at most a fixed number of Items, keeping those with
+// the highest priorities. When it must evict one of several items tied for
+// the lowest priority, Policy decides which by the order they were pushed.
+type BoundedIntQueue struct {
+	q   IntQueue
+	cap int
+	seq uint64 // The seq given to the next admitted item.
+
+	// Policy chooses the victim among tied lowest-priority items.
+	Policy EvictionPolicy
+}
+
+// An EvictionPolicy decides which of several items tied for the lowest
+// priority a full BoundedIntQueue evicts. An offered item with that same
+// priority takes part in the choice as the newest item.
+type EvictionPolicy int
+
+const (
+	// EvictNewest evicts the most recently pushed of the tied items, so an
+	// offered item that only ties is turned away. It is the default.
+	EvictNewest EvictionPolicy = iota
+	// EvictOldest evicts the least recently pushed of the tied items, so an
+	// offered item that ties is admitted in place of the oldest, as in an
+	// LRU cache.
+	EvictOldest
+)
+
+// NewBoundedIntQueue returns a queue that holds at most n items. It panics if
+// n is less than 1.
+func NewBoundedIntQueue(n int) *BoundedIntQueue {
+	if n < 1 {
+		panic("heap: bounded queue capacity must be positive")
+	}
+	return &BoundedIntQueue{q: NewIntQueue(n), cap: n}
+}
+
+// Len returns the number of items in the queue.
+func (b *BoundedIntQueue) Len() int { return b.q.Len() }
+
+// Pop removes and returns the highest-priority item, or reports false if the
+// queue is empty.
+func (b *BoundedIntQueue) Pop() (*Item, bool) { return b.q.TryPop() }
+
+// Offer pushes item if the queue has room. If the queue is full, item is
+// admitted only if its priority is higher than the lowest priority in the
+// queue, or equal to it under EvictOldest, in which case one of the lowest
+// items, chosen by Policy, is removed and returned as evicted.
+//
+// The queue is a max-heap, so finding its lowest items costs O(n): every one
+// of them has to be examined.
+func (b *BoundedIntQueue) Offer(item *Item) (evicted *Item, admitted bool) {
+	if len(b.q) < b.cap {
+		b.push(item)
+		return nil, true
+	}
+	i := b.victim()
+	if p := b.q[i].priority; item.priority < p || item.priority == p && b.Policy != EvictOldest {
+		return nil, false
+	}
+	evicted = heap.Remove(&b.q, i).(*Item)
+	b.push(item)
+	return evicted, true
+}
+
+func (b *BoundedIntQueue) push(item *Item) {
+	item.seq = b.seq
+	b.seq++
+	heap.Push(&b.q, item)
+}
+
+// victim returns the index of the lowest-priority item in the full queue,
+// breaking ties by Policy.
+func (b *BoundedIntQueue) victim() int {
+	v := 0
+	for i, item := range b.q {
+		if b.compare(item, b.q[v]) > 0 {
+			v = i
+		}
+	}
+	return v
+}
+
+// compare orders items from the one kept longest to the first evicted: by
+// decreasing priority and then, among equal priorities, so that the item
+// Policy would evict comes last.
+func (b *BoundedIntQueue) compare(x, y *Item) int {
+	if c := cmp.Compare(y.priority, x.priority); c != 0 {
+		return c
+	}
+	if b.Policy == EvictOldest {
+		return cmp.Compare(y.seq, x.seq)
+	}
+	return cmp.Compare(x.seq, y.seq)
+}
+
+// MergeInto moves every item of other into the queue and then trims it back
+// to capacity, returning the items that did not make the cut in decreasing
+// priority order. Items of other count as pushed after every item already in
+// the queue, in their array order, and ties at the cut are decided by Policy
+// as in Offer. Like IntQueue.Merge it takes over other's Items, so other
+// must not be used afterwards. When trimming is needed all n+m items are
+// sorted once, costing O((n+m) log(n+m)); otherwise the heap is rebuilt in
+// O(n+m).
+func (b *BoundedIntQueue) MergeInto(other IntQueue) (evicted []*Item) {
+	for _, item := range other {
+		item.seq = b.seq
+		b.seq++
+	}
+	b.q.Merge(other)
+	if len(b.q) <= b.cap {
+		return nil
+	}
+	all := b.q
+	slices.SortFunc(all, b.compare)
+	evicted = slices.Clone(all[b.cap:])
+	for _, item := range evicted {
+		item.index = -1 // for safety
+	}
+	clear(all[b.cap:])
+	b.q = Heapify(all[:b.cap])
+	return evicted
+}
+
+// A CappedIntQueue holds at most a fixed number of Items. Unlike
+// BoundedIntQueue it never evicts: pushing to a full queue fails, which lets
+// the caller apply backpressure.
+type CappedIntQueue struct {
+	q   IntQueue
+	cap int
+}
+
+// NewCappedIntQueue returns a queue that holds at most n items. It panics if
+// n is less than 1.
+func NewCappedIntQueue(n int) *CappedIntQueue {
+	if n < 1 {
+		panic("heap: capped queue capacity must be positive")
+	}
+	return &CappedIntQueue{q: NewIntQueue(n), cap: n}
+}
+
+// Len returns the number of items in the queue.
+func (c *CappedIntQueue) Len() int { return c.q.Len() }
+
+// IsFull reports whether the queue has no room left.
+func (c *CappedIntQueue) IsFull() bool { return len(c.q) >= c.cap }
+
+// Remaining returns how many more items can be pushed.
+func (c *CappedIntQueue) Remaining() int { return c.cap - len(c.q) }
+
+// TryPush pushes item, or returns ErrQueueFull if the queue is full.
+func (c *CappedIntQueue) TryPush(item *Item) error {
+	if c.IsFull() {
+		return ErrQueueFull
+	}
+	heap.Push(&c.q, item)
+	return nil
+}
+
+// Pop removes and returns the highest-priority item, or reports false if the
+// queue is empty.
+func (c *CappedIntQueue) Pop() (*Item, bool) { return c.q.TryPop() }
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// itemValues returns the values of items in order.
+func itemValues(items []*Item) []int {
+	vs := make([]int, len(items))
+	for i, item := range items {
+		vs[i] = item.value
+	}
+	return vs
+}
+
+func TestBoundedIntQueueOfferTies(t *testing.T) {
+	tests := []struct {
+		policy      EvictionPolicy
+		higher      int // The victim when a higher item is offered.
+		tieAdmitted bool
+		tie         int // The victim when a tying item is offered.
+	}{
+		{EvictNewest, 3, false, -1},
+		{EvictOldest, 1, true, 2},
+	}
+	for _, tt := range tests {
+		b := NewBoundedIntQueue(4)
+		b.Policy = tt.policy
+		for i, p := range []int{5, 1, 1, 1} {
+			b.Offer(&Item{value: i, priority: p})
+		}
+		evicted, ok := b.Offer(&Item{value: 10, priority: 3})
+		if !ok || evicted.value != tt.higher || evicted.index != -1 {
+			t.Errorf("policy %d: higher offer evicted %v, %v; want value %d", tt.policy, evicted, ok, tt.higher)
+		}
+		evicted, ok = b.Offer(&Item{value: 11, priority: 1})
+		if ok != tt.tieAdmitted {
+			t.Errorf("policy %d: tying offer admitted = %v, want %v", tt.policy, ok, tt.tieAdmitted)
+		} else if ok && evicted.value != tt.tie {
+			t.Errorf("policy %d: tying offer evicted %v, want value %d", tt.policy, evicted, tt.tie)
+		}
+		mustValidate(t, b.q)
+	}
+}
+
+func TestBoundedIntQueueMergeIntoTies(t *testing.T) {
+	tests := []struct {
+		policy EvictionPolicy
+		kept   []int
+	}{
+		// Values 0-7 are queued first, 8-10 merged in; all tie.
+		{EvictOldest, []int{3, 4, 5, 6, 7, 8, 9, 10}},
+		{EvictNewest, []int{0, 1, 2, 3, 4, 5, 6, 7}},
+	}
+	for _, tt := range tests {
+		for trial := 0; trial < 20; trial++ {
+			b := NewBoundedIntQueue(8)
+			b.Policy = tt.policy
+			for i := 0; i < 8; i++ {
+				b.Offer(&Item{value: i, priority: 1})
+			}
+			other := newQueue(1, 1, 1)
+			for i, item := range other {
+				item.value = 8 + i
+			}
+			evicted := b.MergeInto(other)
+			if len(evicted) != 3 || b.Len() != 8 {
+				t.Fatalf("policy %d: evicted %d, kept %d", tt.policy, len(evicted), b.Len())
+			}
+			mustValidate(t, b.q)
+			kept := itemValues(b.q)
+			slices.Sort(kept)
+			if !slices.Equal(kept, tt.kept) {
+				t.Fatalf("policy %d: kept %v, want %v", tt.policy, kept, tt.kept)
+			}
+		}
+	}
+}
+
+func TestBoundedIntQueueMergeIntoPriorities(t *testing.T) {
+	b := NewBoundedIntQueue(3)
+	for i, p := range []int{5, 9, 1} {
+		b.Offer(&Item{value: i, priority: p})
+	}
+	evicted := b.MergeInto(newQueue(7, 2, 8))
+	if got := itemValues(evicted); len(got) != 3 || evicted[0].priority != 5 || evicted[1].priority != 2 || evicted[2].priority != 1 {
+		t.Fatalf("evicted %v", got)
+	}
+	if got := popPriorities(&b.q); !slices.Equal(got, []int{9, 8, 7}) {
+		t.Fatalf("kept %v, want [9 8 7]", got)
+	}
+	if b.MergeInto(nil) != nil {
+		t.Fatal("MergeInto with room to spare evicted items")
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"container/heap"
+	"math/rand"
+	"testing"
+)
+
+func TestBucketIntQueueMatchesStableQueue(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	b := NewBucketIntQueue(255)
+	ref := NewIntQueueStable(0)
+	for i := 0; i < 5000; i++ {
+		if r.Intn(3) > 0 {
+			p := r.Intn(256)
+			b.PushItem(&Item{value: i, priority: p})
+			heap.Push(ref, &Item{value: i, priority: p})
+			continue
+		}
+		got, ok := b.PopItem()
+		if ok != (ref.Len() > 0) {
+			t.Fatalf("PopItem ok = %v with %d items in the reference", ok, ref.Len())
+		}
+		if !ok {
+			continue
+		}
+		if want := heap.Pop(ref).(*Item); got.value != want.value {
+			t.Fatalf("popped %v, want %v", got, want)
+		}
+	}
+	if b.Len() != ref.Len() {
+		t.Fatalf("Len() = %d, want %d", b.Len(), ref.Len())
+	}
+}
+
+func TestBucketIntQueueBoundedGrowth(t *testing.T) {
+	b := NewBucketIntQueue(3)
+	b.PushItem(&Item{priority: 2})
+	for i := 0; i < 100000; i++ {
+		b.PushItem(&Item{value: i, priority: 2})
+		b.PopItem()
+	}
+	if n := len(b.buckets[2].items); n > 4 {
+		t.Fatalf("bucket holds %d slots for 1 live item", n)
+	}
+	if b.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", b.Len())
+	}
+}
+
+func TestBucketIntQueueOutOfRange(t *testing.T) {
+	for _, p := range []int{-1, 256} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("PushItem with priority %d did not panic", p)
+				}
+			}()
+			NewBucketIntQueue(255).PushItem(&Item{priority: p})
+		}()
+	}
+}
+
+// The benchmarks below push and pop through a queue of 1000 items spread
+// over 256 priorities.
+
+func BenchmarkBucketIntQueue(b *testing.B) {
+	q := NewBucketIntQueue(255)
+	for i := 0; i < 1000; i++ {
+		q.PushItem(&Item{priority: i % 256})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		item, _ := q.PopItem()
+		item.priority = i * 7 % 256
+		q.PushItem(item)
+	}
+}
+
+func BenchmarkBucketIntQueueBinaryHeap(b *testing.B) {
+	pq := NewIntQueue(1000)
+	for i := 0; i < 1000; i++ {
+		heap.Push(&pq, &Item{priority: i % 256})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		item := heap.Pop(&pq).(*Item)
+		item.priority = i * 7 % 256
+		heap.Push(&pq, item)
+	}
+}
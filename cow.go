@@ -0,0 +1,60 @@
+package main
+
+import "container/heap"
+
+// A COWIntQueue is a copy-on-write view of an IntQueue, made by Fork. It
+// shares its backing array and Items with the queue it was forked from until
+// its first mutation, which makes a private deep copy first, so forking is
+// O(1) and only forks that are modified pay for a Clone. A mutation in one
+// fork never affects its parent or any sibling.
+type COWIntQueue struct {
+	q      IntQueue
+	shared bool // Whether q may still be shared with another queue.
+}
+
+// Fork returns a copy-on-write view of pq. The view reads pq's array and
+// Items until its first mutation, so pq must not be modified while the view
+// may still share them; fork a COWIntQueue instead to keep branching without
+// that restriction.
+func (pq IntQueue) Fork() *COWIntQueue {
+	return &COWIntQueue{q: pq, shared: true}
+}
+
+// Fork returns a copy-on-write view of c. Afterwards c and the view each make
+// their own copy before their next mutation.
+func (c *COWIntQueue) Fork() *COWIntQueue {
+	c.shared = true
+	return &COWIntQueue{q: c.q, shared: true}
+}
+
+func (c *COWIntQueue) Len() int { return c.q.Len() }
+
+// Peek returns the highest-priority item without removing it, or reports
+// false if the queue is empty. While the view is shared the Item belongs to
+// every queue sharing it and must not be modified.
+func (c *COWIntQueue) Peek() (*Item, bool) { return c.q.PeekOK() }
+
+// PushItem adds item to the queue, first copying it if it is shared.
+func (c *COWIntQueue) PushItem(item *Item) {
+	c.own()
+	heap.Push(&c.q, item)
+}
+
+// PopItem removes and returns the highest-priority item, or reports false if
+// the queue is empty. If the queue was shared, it is copied first and the
+// returned Item is the copy.
+func (c *COWIntQueue) PopItem() (*Item, bool) {
+	if len(c.q) == 0 {
+		return nil, false
+	}
+	c.own()
+	return heap.Pop(&c.q).(*Item), true
+}
+
+// own gives c a private deep copy of its queue if it may be shared.
+func (c *COWIntQueue) own() {
+	if c.shared {
+		c.q = c.q.Clone()
+		c.shared = false
+	}
+}
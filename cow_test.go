@@ -0,0 +1,51 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCOWIntQueueForksAreIndependent(t *testing.T) {
+	pq := newQueue(5, 3, 9, 1)
+	want := pq.PopOrder()
+	a, b := pq.Fork(), pq.Fork()
+
+	a.PopItem()
+	a.PushItem(&Item{value: 50, priority: 100})
+	if got := pq.PopOrder(); !slices.Equal(got, want) {
+		t.Fatalf("parent pops %v after mutating a fork, want %v", got, want)
+	}
+	mustValidate(t, pq)
+	if b.Len() != 4 || b.q.PopOrder()[0] != want[0] {
+		t.Fatalf("sibling changed: %v", b.q.PopOrder())
+	}
+
+	// A fork of a fork is independent of both.
+	c := a.Fork()
+	c.PopItem()
+	if top, _ := a.Peek(); top.priority != 100 || a.Len() != 4 {
+		t.Fatalf("a changed after popping its fork: top %v, len %d", top, a.Len())
+	}
+	a.PopItem()
+	if top, _ := c.Peek(); top.priority != 5 || c.Len() != 3 {
+		t.Fatalf("c changed after popping its parent: top %v, len %d", top, c.Len())
+	}
+	for _, q := range []*COWIntQueue{a, b, c} {
+		mustValidate(t, q.q)
+	}
+}
+
+func TestCOWIntQueueSharesUntilWritten(t *testing.T) {
+	pq := newQueue(5, 3, 9)
+	f := pq.Fork()
+	if top, _ := f.Peek(); top != pq.Peek() {
+		t.Fatal("an unmodified fork does not share its parent's Items")
+	}
+	popped, _ := f.PopItem()
+	if popped == pq.Peek() || pq.Peek().index != 0 {
+		t.Fatal("popping a fork touched its parent's Items")
+	}
+	if _, ok := (IntQueue{}).Fork().PopItem(); ok {
+		t.Fatal("PopItem on an empty fork succeeded")
+	}
+}
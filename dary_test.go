@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestDaryIntQueueOrder(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, d := range []int{2, 3, 4, 8} {
+		q := NewDaryIntQueue(d, 0)
+		var want []int
+		for i := 0; i < 500; i++ {
+			p := r.Intn(100)
+			q.PushItem(&Item{priority: p})
+			want = append(want, p)
+		}
+		slices.Sort(want)
+		slices.Reverse(want)
+		var got []int
+		for {
+			item, ok := q.PopItem()
+			if !ok {
+				break
+			}
+			if item.index != -1 {
+				t.Fatalf("d=%d: popped item has index %d", d, item.index)
+			}
+			got = append(got, item.priority)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("d=%d: popped out of order", d)
+		}
+	}
+}
+
+func BenchmarkDaryIntQueue(b *testing.B) {
+	for _, d := range []int{2, 4, 8} {
+		b.Run(fmt.Sprintf("d=%d", d), func(b *testing.B) {
+			r := rand.New(rand.NewSource(1))
+			q := NewDaryIntQueue(d, 10001)
+			for i := 0; i < 10000; i++ {
+				q.PushItem(&Item{priority: r.Intn(1 << 20)})
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				item, _ := q.PopItem()
+				item.priority = r.Intn(1 << 20)
+				q.PushItem(item)
+			}
+		})
+	}
+}
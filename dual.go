@@ -0,0 +1,131 @@
+package main
+
+import "container/heap"
+
+// A DualEndedQueue gives O(log n) access to both its highest- and its
+// lowest-priority item by keeping every item in two heaps at once, a max-heap
+// and a min-heap. Removing an item from one heap only marks it removed in the
+// other, its twin, which discards it once it surfaces at the top, so each pop
+// sifts one heap. Like LazyIntQueue, a heap that comes to hold more removed
+// entries than live ones is compacted in O(n), which keeps every operation
+// O(log n) amortized and bounds the memory removed items hold to one entry
+// per live item.
+//
+// The Items' index fields are not used, since an Item is at a different
+// position in each heap; they are -1 while items are queued.
+type DualEndedQueue struct {
+	max, min dualHeap
+	live     int
+}
+
+// A dualEntry is shared by both heaps of a DualEndedQueue.
+type dualEntry struct {
+	item    *Item
+	removed bool
+}
+
+func NewDualEndedQueue(n int) *DualEndedQueue {
+	return &DualEndedQueue{
+		max: dualHeap{es: make([]*dualEntry, 0, n)},
+		min: dualHeap{es: make([]*dualEntry, 0, n), min: true},
+	}
+}
+
+// Len returns the number of items in the queue.
+func (d *DualEndedQueue) Len() int { return d.live }
+
+// PushItem adds item to the queue.
+func (d *DualEndedQueue) PushItem(item *Item) {
+	item.index = -1
+	e := &dualEntry{item: item}
+	heap.Push(&d.max, e)
+	heap.Push(&d.min, e)
+	d.live++
+}
+
+// PopMax removes and returns the highest-priority item, or reports false if
+// the queue is empty.
+func (d *DualEndedQueue) PopMax() (*Item, bool) { return d.pop(&d.max, &d.min) }
+
+// PopMin removes and returns the lowest-priority item, or reports false if
+// the queue is empty.
+func (d *DualEndedQueue) PopMin() (*Item, bool) { return d.pop(&d.min, &d.max) }
+
+// PeekMax returns the highest-priority item without removing it, or reports
+// false if the queue is empty.
+func (d *DualEndedQueue) PeekMax() (*Item, bool) { return d.max.peek() }
+
+// PeekMin returns the lowest-priority item without removing it, or reports
+// false if the queue is empty.
+func (d *DualEndedQueue) PeekMin() (*Item, bool) { return d.min.peek() }
+
+func (d *DualEndedQueue) pop(h, twin *dualHeap) (*Item, bool) {
+	h.skipRemoved()
+	if len(h.es) == 0 {
+		return nil, false
+	}
+	e := heap.Pop(h).(*dualEntry)
+	e.removed = true
+	d.live--
+	for _, side := range [...]*dualHeap{h, twin} {
+		if len(side.es) > 2*d.live {
+			side.compact()
+		}
+	}
+	return e.item, true
+}
+
+// dualHeap implements heap.Interface for one side of a DualEndedQueue.
+type dualHeap struct {
+	es  []*dualEntry
+	min bool // Whether the lowest priority is on top.
+}
+
+func (h dualHeap) Len() int { return len(h.es) }
+
+func (h dualHeap) Less(i, j int) bool {
+	if h.min {
+		return h.es[i].item.priority < h.es[j].item.priority
+	}
+	return h.es[i].item.priority > h.es[j].item.priority
+}
+
+func (h dualHeap) Swap(i, j int) { h.es[i], h.es[j] = h.es[j], h.es[i] }
+
+func (h *dualHeap) Push(x interface{}) { h.es = append(h.es, x.(*dualEntry)) }
+
+func (h *dualHeap) Pop() interface{} {
+	n := len(h.es) - 1
+	e := h.es[n]
+	h.es[n] = nil
+	h.es = h.es[:n]
+	return e
+}
+
+// peek returns the top live item, discarding removed entries above it.
+func (h *dualHeap) peek() (*Item, bool) {
+	h.skipRemoved()
+	if len(h.es) == 0 {
+		return nil, false
+	}
+	return h.es[0].item, true
+}
+
+func (h *dualHeap) skipRemoved() {
+	for len(h.es) > 0 && h.es[0].removed {
+		heap.Pop(h)
+	}
+}
+
+// compact drops every removed entry and rebuilds the heap in O(n).
+func (h *dualHeap) compact() {
+	kept := h.es[:0]
+	for _, e := range h.es {
+		if !e.removed {
+			kept = append(kept, e)
+		}
+	}
+	clear(h.es[len(kept):])
+	h.es = kept
+	heap.Init(h)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestDualEndedQueueRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 200; round++ {
+		d := NewDualEndedQueue(0)
+		var ref []int // Kept sorted.
+		for step := 0; step < 300; step++ {
+			switch r.Intn(4) {
+			case 0, 1:
+				p := r.Intn(50)
+				d.PushItem(&Item{value: step, priority: p})
+				i, _ := slices.BinarySearch(ref, p)
+				ref = slices.Insert(ref, i, p)
+			case 2:
+				item, ok := d.PopMax()
+				if ok != (len(ref) > 0) {
+					t.Fatalf("PopMax ok = %v with %d queued", ok, len(ref))
+				}
+				if ok {
+					if want := ref[len(ref)-1]; item.priority != want {
+						t.Fatalf("PopMax = %d, want %d", item.priority, want)
+					}
+					ref = ref[:len(ref)-1]
+				}
+			case 3:
+				item, ok := d.PopMin()
+				if ok != (len(ref) > 0) {
+					t.Fatalf("PopMin ok = %v with %d queued", ok, len(ref))
+				}
+				if ok {
+					if item.priority != ref[0] {
+						t.Fatalf("PopMin = %d, want %d", item.priority, ref[0])
+					}
+					ref = ref[1:]
+				}
+			}
+			if d.Len() != len(ref) {
+				t.Fatalf("Len() = %d, want %d", d.Len(), len(ref))
+			}
+			// Compaction keeps each heap within twice the live items.
+			if n := max(len(d.max.es), len(d.min.es)); n > 2*len(ref)+1 {
+				t.Fatalf("heaps hold %d entries for %d items", n, len(ref))
+			}
+			hi, okHi := d.PeekMax()
+			lo, okLo := d.PeekMin()
+			if okHi != (len(ref) > 0) || okLo != okHi {
+				t.Fatalf("Peek ok = %v, %v with %d queued", okHi, okLo, len(ref))
+			}
+			if okHi && (hi.priority != ref[len(ref)-1] || lo.priority != ref[0]) {
+				t.Fatalf("ends %d, %d, want %d, %d", lo.priority, hi.priority, ref[0], ref[len(ref)-1])
+			}
+		}
+	}
+}
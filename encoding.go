@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// itemFields holds the serialized fields of an Item. The index is not
+// stored; it is derived again when a queue is decoded.
+type itemFields struct {
+	Value    int `json:"value"`
+	Priority int `json:"priority"`
+}
+
+func (pq IntQueue) fields() []itemFields {
+	f := make([]itemFields, len(pq))
+	for i, item := range pq {
+		f[i] = itemFields{item.value, item.priority}
+	}
+	return f
+}
+
+// setFields replaces the contents of pq with new Items built from f and
+// restores the heap invariant, whatever order f is in.
+func (pq *IntQueue) setFields(f []itemFields) {
+	a := make(IntQueue, len(f))
+	for i, fi := range f {
+		a[i] = &Item{value: fi.Value, priority: fi.Priority, index: i}
+	}
+	heap.Init(&a)
+	*pq = a
+}
+
+// MarshalJSON encodes the queue as an array of value/priority objects in the
+// queue's internal order.
+func (pq IntQueue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pq.fields())
+}
+
+// UnmarshalJSON replaces the contents of pq with the items encoded in data
+// and rebuilds the heap.
+func (pq *IntQueue) UnmarshalJSON(data []byte) error {
+	var f []itemFields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	pq.setFields(f)
+	return nil
+}
+
+// GobEncode encodes the queue's value/priority pairs in its internal order.
+func (pq IntQueue) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(pq.fields()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode replaces the contents of pq with the items encoded in data and
+// rebuilds the heap.
+func (pq *IntQueue) GobDecode(data []byte) error {
+	var f []itemFields
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&f); err != nil {
+		return err
+	}
+	pq.setFields(f)
+	return nil
+}
+
+// The binary format written by WriteTo is the item count followed by each
+// item's value and priority, all as little-endian 64-bit integers.
+const (
+	binaryHeaderSize = 8
+	binaryItemSize   = 16
+	binaryChunkItems = 256 // Items encoded per Write call.
+)
+
+// WriteTo writes the queue to w in a compact binary format that
+// ReadQueueFrom can load, and returns the number of bytes written.
+func (pq IntQueue) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	write := func(b []byte) error {
+		n, err := w.Write(b)
+		total += int64(n)
+		if err == nil && n < len(b) {
+			err = io.ErrShortWrite
+		}
+		return err
+	}
+	buf := make([]byte, 0, binaryChunkItems*binaryItemSize)
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(len(pq)))
+	if err := write(buf); err != nil {
+		return total, err
+	}
+	for len(pq) > 0 {
+		chunk := pq[:min(len(pq), binaryChunkItems)]
+		pq = pq[len(chunk):]
+		buf = buf[:0]
+		for _, item := range chunk {
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(item.value))
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(item.priority))
+		}
+		if err := write(buf); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// ReadQueueFrom reads a queue written by WriteTo and rebuilds the heap. If r
+// ends early it returns an error wrapping io.ErrUnexpectedEOF, or io.EOF if
+// r was empty.
+func ReadQueueFrom(r io.Reader) (IntQueue, error) {
+	var buf [binaryItemSize]byte
+	if _, err := io.ReadFull(r, buf[:binaryHeaderSize]); err != nil {
+		return nil, fmt.Errorf("heap: reading queue length: %w", err)
+	}
+	n := binary.LittleEndian.Uint64(buf[:binaryHeaderSize])
+	// Do not trust n for the allocation; a corrupt header could claim anything.
+	f := make([]itemFields, 0, min(n, 1<<16))
+	for i := uint64(0); i < n; i++ {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, fmt.Errorf("heap: reading item %d of %d: %w", i, n, err)
+		}
+		f = append(f, itemFields{
+			Value:    int(binary.LittleEndian.Uint64(buf[:8])),
+			Priority: int(binary.LittleEndian.Uint64(buf[8:])),
+		})
+	}
+	var pq IntQueue
+	pq.setFields(f)
+	return pq, nil
+}
+
+// WriteCSV writes the queue to w as CSV for spreadsheets and other offline
+// tools: a priority,value header and then one row per item in decreasing
+// priority order. The rows come from a copy of the array, so the queue is not
+// changed. It returns the first write error, if any.
+func (pq IntQueue) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"priority", "value"})
+	for item := range pq.Ordered() {
+		cw.Write([]string{strconv.Itoa(item.priority), strconv.Itoa(item.value)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"container/heap"
+	"time"
+)
+
+// An ExpiryQueue holds values that become ready at a deadline, keeping the
+// earliest deadline on top. Each Item's priority is its deadline in Unix
+// nanoseconds, so deadlines must fall between the years 1678 and 2262 and int
+// must be 64 bits wide.
+type ExpiryQueue struct {
+	q *OrderedIntQueue
+}
+
+func NewExpiryQueue(n int) *ExpiryQueue {
+	return &ExpiryQueue{q: NewIntQueueMin(n)}
+}
+
+func (e *ExpiryQueue) Len() int { return e.q.Len() }
+
+// PushAt adds value to the queue, to become ready at the given time, and
+// returns its Item.
+func (e *ExpiryQueue) PushAt(value int, at time.Time) *Item {
+	item := &Item{value: value, priority: int(at.UnixNano())}
+	heap.Push(e.q, item)
+	return item
+}
+
+// PopReady removes and returns the item with the earliest deadline if that
+// deadline is not after now. Otherwise, or if the queue is empty, it reports
+// false.
+func (e *ExpiryQueue) PopReady(now time.Time) (*Item, bool) {
+	if e.q.Len() == 0 || e.q.items[0].priority > int(now.UnixNano()) {
+		return nil, false
+	}
+	return heap.Pop(e.q).(*Item), true
+}
+
+// NextDeadline returns the earliest deadline in the queue, or reports false
+// if the queue is empty. A caller can sleep until then before calling
+// PopReady.
+func (e *ExpiryQueue) NextDeadline() (time.Time, bool) {
+	if e.q.Len() == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(e.q.items[0].priority)), true
+}
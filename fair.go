@@ -0,0 +1,53 @@
+package main
+
+import "container/heap"
+
+// fairScale is the virtual time an item of weight 1 takes to be served.
+// Heavier items take fairScale/weight.
+const fairScale = 1 << 20
+
+// A WeightedFairQueue serves items in proportion to their weights instead of
+// in strict priority order, so light items are not starved: while both are
+// queued, items of weight 3 are popped about three times as often as items
+// of weight 1.
+//
+// Items pushed with the same weight form one flow. Each item is stamped with
+// a virtual finish time, one service time, fairScale/weight, after the later
+// of the previous item in its flow and the finish time of the last item
+// popped, and items are popped by earliest finish time (self-clocked fair
+// queueing).
+type WeightedFairQueue struct {
+	q      PriorityQueue[*Item] // Priorities are negated finish times.
+	now    int                  // The finish time of the last popped item.
+	finish map[int]int          // The finish time of the last item pushed per weight.
+}
+
+func NewWeightedFairQueue(n int) *WeightedFairQueue {
+	return &WeightedFairQueue{q: NewPriorityQueue[*Item](n), finish: make(map[int]int)}
+}
+
+func (w *WeightedFairQueue) Len() int { return w.q.Len() }
+
+// Push adds value with the given weight and returns its Item, whose priority
+// is the weight. It panics if weight is less than 1.
+func (w *WeightedFairQueue) Push(value, weight int) *Item {
+	if weight < 1 {
+		panic("heap: weight must be positive")
+	}
+	f := max(w.now, w.finish[weight]) + max(fairScale/weight, 1)
+	w.finish[weight] = f
+	item := &Item{value: value, priority: weight, index: -1}
+	heap.Push(&w.q, &Element[*Item]{Value: item, Priority: -f})
+	return item
+}
+
+// Pop removes and returns the item with the earliest virtual finish time, or
+// reports false if the queue is empty.
+func (w *WeightedFairQueue) Pop() (*Item, bool) {
+	if len(w.q) == 0 {
+		return nil, false
+	}
+	e := heap.Pop(&w.q).(*Element[*Item])
+	w.now = -e.Priority
+	return e.Value, true
+}
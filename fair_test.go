@@ -0,0 +1,42 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWeightedFairQueueServedRatio(t *testing.T) {
+	w := NewWeightedFairQueue(0)
+	for i := 0; i < 10000; i++ {
+		w.Push(i, 3)
+		w.Push(i, 1)
+	}
+	// While both flows stay backlogged, weight 3 is served three times as
+	// often as weight 1.
+	served := map[int]int{}
+	for i := 0; i < 4000; i++ {
+		item, ok := w.Pop()
+		if !ok {
+			t.Fatal("Pop failed with items queued")
+		}
+		served[item.priority]++
+	}
+	if ratio := float64(served[3]) / float64(served[1]); math.Abs(ratio-3) > 0.05 {
+		t.Fatalf("served %d of weight 3 and %d of weight 1, ratio %.3f", served[3], served[1], ratio)
+	}
+}
+
+func TestWeightedFairQueueFIFOWithinFlow(t *testing.T) {
+	w := NewWeightedFairQueue(0)
+	for i := 0; i < 5; i++ {
+		w.Push(i, 2)
+	}
+	for want := 0; want < 5; want++ {
+		if item, _ := w.Pop(); item.value != want {
+			t.Fatalf("popped %d, want %d", item.value, want)
+		}
+	}
+	if _, ok := w.Pop(); ok {
+		t.Fatal("Pop succeeded on an empty queue")
+	}
+}
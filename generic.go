@@ -0,0 +1,117 @@
+package main
+
+import (
+	"cmp"
+	"container/heap"
+)
+
+// An Element is something we manage in a PriorityQueue. It plays the role of
+// Item for queues whose values are not ints.
+type Element[T any] struct {
+	Value    T   // The value of the element; arbitrary.
+	Priority int // The priority of the element in the queue.
+	// The index is needed by heap.Fix and is maintained by the heap.Interface methods.
+	index int // The index of the element in the heap.
+}
+
+// A PriorityQueue implements heap.Interface and holds Elements of any value
+// type. It orders its elements exactly like IntQueue does.
+type PriorityQueue[T any] []*Element[T]
+
+func NewPriorityQueue[T any](n int) PriorityQueue[T] {
+	return make(PriorityQueue[T], 0, n)
+}
+
+func (pq PriorityQueue[T]) Len() int { return len(pq) }
+
+func (pq PriorityQueue[T]) Less(i, j int) bool {
+	// We want Pop to give us the highest, not lowest, priority so we use greater than here.
+	return pq[i].Priority > pq[j].Priority
+}
+
+func (pq PriorityQueue[T]) Swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+	pq[i].index = i
+	pq[j].index = j
+}
+
+func (pq *PriorityQueue[T]) Push(x interface{}) {
+	a := *pq
+	n := len(a)
+	a = append(a, x.(*Element[T]))
+	a[n].index = n
+	*pq = a
+}
+
+func (pq *PriorityQueue[T]) Pop() interface{} {
+	a := *pq
+	n := len(a)
+	e := a[n-1]
+	e.index = -1 // for safety
+	*pq = a[0 : n-1]
+	return e
+}
+
+// Push adds e to pq, restoring the heap invariant.
+func Push[T any](pq *PriorityQueue[T], e *Element[T]) {
+	heap.Push(pq, e)
+}
+
+// Pop removes and returns the highest-priority element of pq. It panics if
+// pq is empty.
+func Pop[T any](pq *PriorityQueue[T]) *Element[T] {
+	return heap.Pop(pq).(*Element[T])
+}
+
+// Peek returns the highest-priority element of pq without removing it. It
+// panics if pq is empty.
+func Peek[T any](pq PriorityQueue[T]) *Element[T] {
+	return pq[0]
+}
+
+// An Entry is something we manage in a Queue: an int value whose priority
+// may be of any ordered type, such as a float64 score or a string key.
+type Entry[P cmp.Ordered] struct {
+	Value    int // The value of the entry; arbitrary.
+	Priority P   // The priority of the entry in the queue.
+	index    int // The index of the entry in the heap.
+}
+
+// A Queue implements heap.Interface and holds Entries, popping the highest
+// priority first. A NaN priority is treated as lower than every other
+// priority, so NaNs come out last and cannot break the heap invariant.
+type Queue[P cmp.Ordered] []*Entry[P]
+
+func NewQueue[P cmp.Ordered](n int) Queue[P] {
+	return make(Queue[P], 0, n)
+}
+
+func (pq Queue[P]) Len() int { return len(pq) }
+
+func (pq Queue[P]) Less(i, j int) bool {
+	// cmp.Compare orders NaN before every other value, which makes it lowest here.
+	return cmp.Compare(pq[i].Priority, pq[j].Priority) > 0
+}
+
+func (pq Queue[P]) Swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+	pq[i].index = i
+	pq[j].index = j
+}
+
+func (pq *Queue[P]) Push(x interface{}) {
+	a := *pq
+	n := len(a)
+	a = append(a, x.(*Entry[P]))
+	a[n].index = n
+	*pq = a
+}
+
+func (pq *Queue[P]) Pop() interface{} {
+	a := *pq
+	n := len(a)
+	e := a[n-1]
+	e.index = -1 // for safety
+	*pq = a[0 : n-1]
+	return e
+}
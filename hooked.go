@@ -0,0 +1,42 @@
+package main
+
+import "container/heap"
+
+// A HookedIntQueue is an IntQueue that calls back whenever an item enters or
+// leaves it. The callbacks run synchronously on the goroutine that pushed or
+// popped, once per item, after the heap has been restored; reordering inside
+// the heap does not trigger them.
+type HookedIntQueue struct {
+	q IntQueue
+
+	OnPush func(*Item) // If non-nil, called with each pushed item.
+	OnPop  func(*Item) // If non-nil, called with each popped item.
+}
+
+func NewHookedIntQueue(n int) *HookedIntQueue {
+	return &HookedIntQueue{q: NewIntQueue(n)}
+}
+
+func (h *HookedIntQueue) Len() int { return h.q.Len() }
+
+// PushItem adds item to the queue.
+func (h *HookedIntQueue) PushItem(item *Item) {
+	heap.Push(&h.q, item)
+	if h.OnPush != nil {
+		h.OnPush(item)
+	}
+}
+
+// PopItem removes and returns the highest-priority item, or reports false if
+// the queue is empty.
+func (h *HookedIntQueue) PopItem() (*Item, bool) {
+	item, ok := h.q.TryPop()
+	if ok && h.OnPop != nil {
+		h.OnPop(item)
+	}
+	return item, ok
+}
+
+// Peek returns the highest-priority item without removing it, or reports
+// false if the queue is empty. It does not call OnPop.
+func (h *HookedIntQueue) Peek() (*Item, bool) { return h.q.PeekOK() }
@@ -0,0 +1,59 @@
+package main
+
+import "container/heap"
+
+// An IntPriorityQueue is the common interface of the queues that hold Items
+// and pop the highest priority first, so code can be written once and run
+// against a binary, d-ary or lazy-deleting heap chosen at run time.
+type IntPriorityQueue interface {
+	// PushItem adds item to the queue.
+	PushItem(item *Item)
+	// PopItem removes and returns the highest-priority item, or reports
+	// false if the queue is empty.
+	PopItem() (*Item, bool)
+	// Peek returns the highest-priority item without removing it, or
+	// reports false if the queue is empty.
+	Peek() (*Item, bool)
+	// Len returns the number of items in the queue that PopItem can still
+	// return, so a loop popping while Len is positive always gets an item.
+	Len() int
+}
+
+var (
+	_ IntPriorityQueue = intQueueAdapter{}
+	_ IntPriorityQueue = lazyAdapter{}
+	_ IntPriorityQueue = (*DaryIntQueue)(nil)
+	_ IntPriorityQueue = (*HookedIntQueue)(nil)
+	_ IntPriorityQueue = (*TracedIntQueue)(nil)
+	_ IntPriorityQueue = (*COWIntQueue)(nil)
+	_ IntPriorityQueue = (*BucketIntQueue)(nil)
+	_ IntPriorityQueue = (*AuditedIntQueue)(nil)
+)
+
+// AsPriorityQueue returns pq as an IntPriorityQueue. IntQueue cannot satisfy
+// the interface itself, since its Peek returns only the item, so this wraps
+// it; the wrapper and pq stay the same queue.
+func (pq *IntQueue) AsPriorityQueue() IntPriorityQueue { return intQueueAdapter{pq} }
+
+type intQueueAdapter struct {
+	pq *IntQueue
+}
+
+func (a intQueueAdapter) PushItem(item *Item) { heap.Push(a.pq, item) }
+
+func (a intQueueAdapter) PopItem() (*Item, bool) { return a.pq.TryPop() }
+
+func (a intQueueAdapter) Peek() (*Item, bool) { return a.pq.PeekOK() }
+
+func (a intQueueAdapter) Len() int { return a.pq.Len() }
+
+// AsPriorityQueue returns l as an IntPriorityQueue. LazyIntQueue's own Len
+// counts cancelled items, which PopItem never returns, so the wrapper's Len
+// reports LiveLen instead; the wrapper and l stay the same queue.
+func (l *LazyIntQueue) AsPriorityQueue() IntPriorityQueue { return lazyAdapter{l} }
+
+type lazyAdapter struct {
+	*LazyIntQueue
+}
+
+func (a lazyAdapter) Len() int { return a.LiveLen() }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIntPriorityQueueImplementations(t *testing.T) {
+	pq := NewIntQueue(0)
+	queues := map[string]IntPriorityQueue{
+		"IntQueue": pq.AsPriorityQueue(),
+		"Dary":     NewDaryIntQueue(4, 0),
+		"Lazy":     NewLazyIntQueue(0).AsPriorityQueue(),
+		"Hooked":   NewHookedIntQueue(0),
+		"Traced":   NewTracedIntQueue(0),
+		"Bucket":   NewBucketIntQueue(100),
+		"Audited":  NewAuditedIntQueue(0),
+	}
+	for name, q := range queues {
+		for _, p := range []int{3, 9, 5, 7} {
+			q.PushItem(&Item{priority: p})
+		}
+		if top, ok := q.Peek(); !ok || top.priority != 9 {
+			t.Errorf("%s: Peek() = %v, %v", name, top, ok)
+		}
+		var got []int
+		for q.Len() > 0 {
+			item, ok := q.PopItem()
+			if !ok {
+				t.Fatalf("%s: PopItem failed with Len() = %d", name, q.Len())
+			}
+			got = append(got, item.priority)
+		}
+		if !slices.Equal(got, []int{9, 7, 5, 3}) {
+			t.Errorf("%s: popped %v", name, got)
+		}
+		if _, ok := q.PopItem(); ok {
+			t.Errorf("%s: PopItem succeeded on an empty queue", name)
+		}
+	}
+}
+
+func TestLazyAdapterLenSkipsCancelled(t *testing.T) {
+	l := NewLazyIntQueue(0)
+	a, b := &Item{priority: 1}, &Item{priority: 2}
+	l.PushItem(a)
+	l.PushItem(b)
+	l.PushItem(&Item{priority: 3})
+	l.Cancel(a)
+	q := l.AsPriorityQueue()
+	n := 0
+	for q.Len() > 0 {
+		if _, ok := q.PopItem(); !ok {
+			t.Fatalf("PopItem failed with Len() = %d", q.Len())
+		}
+		n++
+	}
+	if n != 2 {
+		t.Fatalf("popped %d items, want 2", n)
+	}
+}
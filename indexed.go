@@ -0,0 +1,65 @@
+package main
+
+import "container/heap"
+
+// An IndexedIntQueue implements heap.Interface and holds Items like IntQueue,
+// but also tracks where each value sits in the heap, so an item can be found
+// by its value in O(1). Every value in the queue must be unique; pushing a
+// value that is already queued panics.
+type IndexedIntQueue struct {
+	items IntQueue
+	pos   map[int]int // The heap index of each queued value.
+}
+
+func NewIndexedIntQueue(n int) *IndexedIntQueue {
+	return &IndexedIntQueue{items: NewIntQueue(n), pos: make(map[int]int, n)}
+}
+
+func (q *IndexedIntQueue) Len() int { return len(q.items) }
+
+func (q *IndexedIntQueue) Less(i, j int) bool { return q.items.Less(i, j) }
+
+func (q *IndexedIntQueue) Swap(i, j int) {
+	q.items.Swap(i, j)
+	q.pos[q.items[i].value] = i
+	q.pos[q.items[j].value] = j
+}
+
+func (q *IndexedIntQueue) Push(x interface{}) {
+	item := x.(*Item)
+	if _, ok := q.pos[item.value]; ok {
+		panic("heap: value is already in the queue")
+	}
+	q.pos[item.value] = len(q.items)
+	q.items.Push(item)
+}
+
+func (q *IndexedIntQueue) Pop() interface{} {
+	item := q.items.Pop().(*Item)
+	delete(q.pos, item.value)
+	return item
+}
+
+// UpdatePriority sets the priority of the item holding value and restores
+// the heap. It reports false if no queued item holds value.
+func (q *IndexedIntQueue) UpdatePriority(value, priority int) bool {
+	i, ok := q.pos[value]
+	if !ok {
+		return false
+	}
+	q.items[i].priority = priority
+	heap.Fix(q, i)
+	return true
+}
+
+// Upsert sets the priority of the item holding value and restores the heap,
+// or pushes a new Item if there is none, and returns the item either way. It
+// costs O(log n).
+func (q *IndexedIntQueue) Upsert(value, priority int) *Item {
+	if q.UpdatePriority(value, priority) {
+		return q.items[q.pos[value]]
+	}
+	item := &Item{value: value, priority: priority}
+	heap.Push(q, item)
+	return item
+}
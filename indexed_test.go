@@ -0,0 +1,42 @@
+package main
+
+import (
+	"container/heap"
+	"testing"
+)
+
+func TestIndexedIntQueueUpsert(t *testing.T) {
+	q := NewIndexedIntQueue(0)
+	for _, item := range newQueue(5, 9, 7) {
+		heap.Push(q, item)
+	}
+
+	inserted := q.Upsert(10, 3)
+	if inserted.value != 10 || q.Len() != 4 {
+		t.Fatalf("insert: got %v, len %d", inserted, q.Len())
+	}
+	existing := q.items[q.pos[0]]
+	if got := q.Upsert(0, 100); got != existing || q.Len() != 4 {
+		t.Fatalf("update returned %v, want the existing item", got)
+	}
+	if q.items[0] != existing {
+		t.Fatalf("updated item did not move to the root: top is %v", q.items[0])
+	}
+	mustValidate(t, q.items)
+	for v, i := range q.pos {
+		if q.items[i].value != v {
+			t.Fatalf("pos[%d] = %d, which holds %v", v, i, q.items[i])
+		}
+	}
+}
+
+func TestIndexedIntQueueDuplicatePanics(t *testing.T) {
+	q := NewIndexedIntQueue(0)
+	heap.Push(q, &Item{value: 1})
+	defer func() {
+		if recover() == nil {
+			t.Fatal("pushing a duplicate value did not panic")
+		}
+	}()
+	heap.Push(q, &Item{value: 1})
+}
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestPushPastCapacity(t *testing.T) {
+	pq := NewIntQueue(2)
+	for i := 0; i < 10; i++ {
+		heap.Push(&pq, &Item{value: i, priority: i})
+	}
+	if pq.Len() != 10 {
+		t.Fatalf("Len() = %d, want 10", pq.Len())
+	}
+	for want := 9; want >= 0; want-- {
+		if got := heap.Pop(&pq).(*Item).priority; got != want {
+			t.Fatalf("popped priority %d, want %d", got, want)
+		}
+	}
+}
+
+// newQueue returns a heap of Items whose values are their positions in
+// priorities.
+func newQueue(priorities ...int) IntQueue {
+	items := make([]*Item, len(priorities))
+	for i, p := range priorities {
+		items[i] = &Item{value: i, priority: p}
+	}
+	return NewIntQueueFromSlice(items)
+}
+
+func mustValidate(t *testing.T, pq IntQueue) {
+	t.Helper()
+	if err := pq.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// popPriorities drains pq and returns the priorities in pop order.
+func popPriorities(pq *IntQueue) []int {
+	var ps []int
+	for pq.Len() > 0 {
+		ps = append(ps, heap.Pop(pq).(*Item).priority)
+	}
+	return ps
+}
+
+func TestReplaceAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		index    int
+		priority int
+	}{
+		{"root down", 0, 1},
+		{"root stays", 0, 100},
+		{"interior up", 1, 100},
+		{"interior down", 1, 0},
+		{"leaf up", 6, 100},
+		{"leaf stays", 6, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pq := newQueue(9, 8, 7, 6, 5, 4, 3)
+			old := pq[tt.index]
+			item := &Item{value: 50, priority: tt.priority}
+			pq.ReplaceAt(tt.index, item)
+			mustValidate(t, pq)
+			if old.index != -1 {
+				t.Errorf("replaced item has index %d, want -1", old.index)
+			}
+			if pq[item.index] != item {
+				t.Errorf("new item records index %d but is not there", item.index)
+			}
+			if pq.Contains(old.value) {
+				t.Errorf("replaced item is still in the queue")
+			}
+		})
+	}
+}
+
+func TestReplaceAtSameItem(t *testing.T) {
+	for _, priority := range []int{100, 1} {
+		pq := newQueue(9, 8, 7, 6, 5, 4, 3)
+		item := pq[1]
+		item.priority = priority
+		pq.ReplaceAt(1, item)
+		mustValidate(t, pq)
+		if pq.Len() != 7 || pq[item.index] != item {
+			t.Fatalf("priority %d: item at index %d lost", priority, item.index)
+		}
+	}
+}
+
+func TestReplaceTopReschedule(t *testing.T) {
+	for _, priority := range []int{45, 100, 1} {
+		pq := newQueue(50, 40, 30, 20, 10)
+		top := pq.Peek()
+		top.priority = priority
+		if got := pq.ReplaceTop(top); got != top {
+			t.Fatalf("ReplaceTop returned %v, want the top itself", got)
+		}
+		mustValidate(t, pq)
+		if pq.Len() != 5 || pq[top.index] != top {
+			t.Fatalf("priority %d: rescheduled top lost, index %d", priority, top.index)
+		}
+	}
+}
+
+func TestPopRange(t *testing.T) {
+	pq := newQueue(5, 9, 7, 1, 3, 8, 6, 7)
+	got := pq.PopRange(3, 7)
+	var ps []int
+	for _, item := range got {
+		if item.index != -1 {
+			t.Errorf("removed item %v has index %d", item, item.index)
+		}
+		ps = append(ps, item.priority)
+	}
+	if !slices.Equal(ps, []int{7, 7, 6, 5, 3}) {
+		t.Errorf("PopRange(3, 7) = %v", ps)
+	}
+	mustValidate(t, pq)
+	if rest := popPriorities(&pq); !slices.Equal(rest, []int{9, 8, 1}) {
+		t.Errorf("left %v, want [9 8 1]", rest)
+	}
+	if pq.PopRange(1, 0) != nil {
+		t.Error("PopRange over an empty window removed items")
+	}
+}
+
+// benchQueue returns a queue of 1000 items with random priorities and room
+// to grow.
+func benchQueue() IntQueue {
+	r := rand.New(rand.NewSource(1))
+	pq := NewIntQueue(2000)
+	for i := 0; i < 1000; i++ {
+		pq = append(pq, &Item{value: i, priority: r.Intn(1 << 20), index: i})
+	}
+	heap.Init(&pq)
+	return pq
+}
+
+func BenchmarkPushPop(b *testing.B) {
+	pq := benchQueue()
+	r := rand.New(rand.NewSource(2))
+	item := &Item{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		item.priority = r.Intn(1 << 20)
+		item = pq.PushPop(item)
+	}
+}
+
+func BenchmarkPushThenPop(b *testing.B) {
+	pq := benchQueue()
+	r := rand.New(rand.NewSource(2))
+	item := &Item{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		item.priority = r.Intn(1 << 20)
+		heap.Push(&pq, item)
+		item = heap.Pop(&pq).(*Item)
+	}
+}
+
+func TestPushPop(t *testing.T) {
+	pq := newQueue(3, 8, 1, 9, 4)
+	high := &Item{priority: 100}
+	if got := pq.PushPop(high); got != high {
+		t.Fatalf("PushPop(high) = %v, want the item itself", got)
+	}
+	low := &Item{priority: 5}
+	if got := pq.PushPop(low); got.priority != 9 || got.index != -1 {
+		t.Fatalf("PushPop(low) = %v with index %d", got, got.index)
+	}
+	mustValidate(t, pq)
+	var empty IntQueue
+	if empty.PushPop(low) != low {
+		t.Fatal("PushPop on an empty queue did not return the item")
+	}
+}
+
+func TestUpdatePriorityRepairsStaleIndex(t *testing.T) {
+	pq := newQueue(9, 8, 7, 6, 5)
+	item := pq[3]
+	item.index = 1 // stale: points at another item
+	pq.UpdatePriority(item, 100)
+	mustValidate(t, pq)
+	if pq.Peek() != item {
+		t.Fatalf("top is %v, want %v", pq.Peek(), item)
+	}
+
+	item = pq[2]
+	item.index = 42 // out of range
+	pq.UpdatePriority(item, -1)
+	mustValidate(t, pq)
+}
+
+func TestUpdatePriorityPanicsOnForeignItem(t *testing.T) {
+	pq := newQueue(9, 8, 7)
+	popped := heap.Pop(&pq).(*Item)
+	for _, item := range []*Item{popped, {priority: 1, index: 0}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("UpdatePriority(%v) did not panic", item)
+				}
+			}()
+			pq.UpdatePriority(item, 5)
+		}()
+		mustValidate(t, pq)
+	}
+}
+
+func TestAddPriorityBounds(t *testing.T) {
+	tests := []struct {
+		priority, delta int
+		want            int
+		err             error
+	}{
+		{math.MaxInt - 1, 1, math.MaxInt, nil},
+		{math.MaxInt, 1, math.MaxInt, ErrPriorityOverflow},
+		{math.MaxInt, 0, math.MaxInt, nil},
+		{math.MaxInt, math.MinInt, -1, nil},
+		{1, math.MaxInt, 1, ErrPriorityOverflow},
+		{math.MinInt + 1, -1, math.MinInt, nil},
+		{math.MinInt, -1, math.MinInt, ErrPriorityOverflow},
+		{math.MinInt, math.MaxInt, -1, nil},
+		{-1, math.MinInt, -1, ErrPriorityOverflow},
+		{0, math.MinInt, math.MinInt, nil},
+	}
+	for _, tt := range tests {
+		pq := newQueue(0, tt.priority, 5)
+		item := pq[pq.IndexOf(1)]
+		err := pq.AddPriority(item, tt.delta)
+		if err != tt.err || item.priority != tt.want {
+			t.Errorf("AddPriority(%d, %d) = %d, %v; want %d, %v", tt.priority, tt.delta, item.priority, err, tt.want, tt.err)
+		}
+		mustValidate(t, pq)
+	}
+}
+
+// cancelAfterChecks is a context that reports itself cancelled once Err has
+// been called more than n times.
+type cancelAfterChecks struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfterChecks) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestDrainCtxCancelledMidDrain(t *testing.T) {
+	priorities := make([]int, 3000)
+	for i := range priorities {
+		priorities[i] = i
+	}
+	pq := newQueue(priorities...)
+	ctx := &cancelAfterChecks{Context: context.Background(), n: 1}
+	got, err := pq.DrainCtx(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("DrainCtx returned %v, want context.Canceled", err)
+	}
+	if len(got) != 1024 || pq.Len() != 3000-1024 {
+		t.Fatalf("drained %d, left %d; want 1024, %d", len(got), pq.Len(), 3000-1024)
+	}
+	for i, item := range got {
+		if item.priority != 2999-i || item.index != -1 {
+			t.Fatalf("item %d is %v with index %d", i, item, item.index)
+		}
+	}
+	mustValidate(t, pq)
+	if pq.Peek().priority != 2999-1024 {
+		t.Fatalf("top left is %v", pq.Peek())
+	}
+
+	rest, err := pq.DrainCtx(context.Background())
+	if err != nil || len(rest) != 3000-1024 || pq.Len() != 0 {
+		t.Fatalf("second DrainCtx = %d items, %v", len(rest), err)
+	}
+}
+
+func TestDrainCtxAlreadyCancelled(t *testing.T) {
+	pq := newQueue(1, 2, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got, err := pq.DrainCtx(ctx)
+	if len(got) != 0 || !errors.Is(err, context.Canceled) || pq.Len() != 3 {
+		t.Fatalf("DrainCtx = %d items, %v; left %d", len(got), err, pq.Len())
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	pq := newQueue(5, 9, 7)
+
+	inserted := pq.Upsert(10, 3)
+	if inserted.value != 10 || inserted.priority != 3 || pq.Len() != 4 {
+		t.Fatalf("insert: got %v, len %d", inserted, pq.Len())
+	}
+	mustValidate(t, pq)
+
+	existing := pq[pq.IndexOf(0)]
+	if got := pq.Upsert(0, 100); got != existing || pq.Len() != 4 {
+		t.Fatalf("update returned %v, want the existing item", got)
+	}
+	if pq.Peek() != existing {
+		t.Fatalf("updated item did not move to the root: top is %v", pq.Peek())
+	}
+	mustValidate(t, pq)
+
+	pq.Upsert(0, -5)
+	mustValidate(t, pq)
+	if got := popPriorities(&pq); !slices.Equal(got, []int{9, 7, 3, -5}) {
+		t.Fatalf("popped %v", got)
+	}
+}
+
+func TestIncreaseDecreaseKey(t *testing.T) {
+	priorities := []int{90, 80, 70, 60, 50, 40, 30, 20, 10}
+	tests := []struct {
+		name     string
+		index    int
+		priority int
+	}{
+		{"increase root", 0, 95},
+		{"increase child of root", 1, 100},
+		{"increase leaf to root", 8, 100},
+		{"increase leaf one level", 8, 65},
+		{"decrease root to leaf", 0, 0},
+		{"decrease root one level", 0, 85},
+		{"decrease child of root", 2, 5},
+		{"decrease leaf", 8, -10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pq := newQueue(priorities...)
+			item := pq[tt.index]
+			if tt.priority > item.priority {
+				pq.IncreaseKey(item, tt.priority)
+			} else {
+				pq.DecreaseKey(item, tt.priority)
+			}
+			mustValidate(t, pq)
+			if item.priority != tt.priority || pq[item.index] != item {
+				t.Fatalf("item %v misplaced at %d", item, item.index)
+			}
+		})
+	}
+}
+
+func TestIncreaseDecreaseKeyWrongDirection(t *testing.T) {
+	pq := newQueue(3, 2, 1)
+	for name, f := range map[string]func(){
+		"IncreaseKey lower":  func() { pq.IncreaseKey(pq[0], 0) },
+		"DecreaseKey higher": func() { pq.DecreaseKey(pq[2], 10) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s did not panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+	mustValidate(t, pq)
+}
+
+func TestUpdateAll(t *testing.T) {
+	pq := newQueue(5, 9, 7, 1, 3)
+	// Reverse the order: the lowest priority becomes the highest.
+	pq.UpdateAll(func(item *Item) int { return -item.priority })
+	mustValidate(t, pq)
+	if got := popPriorities(&pq); !slices.Equal(got, []int{-1, -3, -5, -7, -9}) {
+		t.Fatalf("popped %v", got)
+	}
+	var empty IntQueue
+	empty.UpdateAll(func(*Item) int { t.Fatal("called on an empty queue"); return 0 })
+}
+
+func TestClamp(t *testing.T) {
+	pq := newQueue(-20, 15, 3, 40, 10, 0, 7)
+	pq.Clamp(0, 10)
+	mustValidate(t, pq)
+	if got := popPriorities(&pq); !slices.Equal(got, []int{10, 10, 10, 7, 3, 0, 0}) {
+		t.Fatalf("popped %v", got)
+	}
+
+	pq = newQueue(-20, 15, 3)
+	pq.Clamp(5, 5)
+	mustValidate(t, pq)
+	if got := popPriorities(&pq); !slices.Equal(got, []int{5, 5, 5}) {
+		t.Fatalf("lo == hi: popped %v", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Clamp(1, 0) did not panic")
+		}
+	}()
+	pq.Clamp(1, 0)
+}
+
+func TestShrinkToFit(t *testing.T) {
+	pq := NewIntQueue(100)
+	for i := 0; i < 100; i++ {
+		heap.Push(&pq, &Item{value: i, priority: i})
+	}
+	for i := 0; i < 90; i++ {
+		heap.Pop(&pq)
+	}
+	pq.ShrinkToFit()
+	if pq.Cap() != 10 || pq.Len() != 10 {
+		t.Fatalf("after shrinking, len %d, cap %d; want 10, 10", pq.Len(), pq.Cap())
+	}
+	mustValidate(t, pq)
+
+	// A queue using at least a quarter of its array is left alone.
+	pq = NewIntQueue(8)
+	heap.Push(&pq, &Item{})
+	heap.Push(&pq, &Item{})
+	pq.ShrinkToFit()
+	if pq.Cap() != 8 {
+		t.Fatalf("cap %d, want 8", pq.Cap())
+	}
+}
+
+func TestNewIntQueueFromPairs(t *testing.T) {
+	pq, err := NewIntQueueFromPairs([]int{1, 2, 3}, []int{5, 9, 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustValidate(t, pq)
+	if got := pq.PopOrder(); !slices.Equal(got, []int{2, 3, 1}) {
+		t.Fatalf("pop order %v, want [2 3 1]", got)
+	}
+
+	pq, err = NewIntQueueFromPairs(nil, []int{})
+	if err != nil || pq.Len() != 0 {
+		t.Fatalf("empty input: len %d, %v", pq.Len(), err)
+	}
+
+	for _, tt := range []struct{ values, priorities []int }{
+		{[]int{1}, nil},
+		{[]int{1, 2}, []int{1, 2, 3}},
+	} {
+		if _, err := NewIntQueueFromPairs(tt.values, tt.priorities); err == nil {
+			t.Errorf("%d values and %d priorities: no error", len(tt.values), len(tt.priorities))
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	var empty IntQueue
+	if n := empty.Compact(); n != 0 || empty.Len() != 0 {
+		t.Fatalf("Compact on an empty queue = %d, len %d", n, empty.Len())
+	}
+
+	pq := newQueue(5, 9, 7, 1, 3)
+	pq[1].deleted = true
+	pq[3].deleted = true
+	pq[0].index = 4 // stale
+	if n := pq.Compact(); n != 2 || pq.Len() != 3 {
+		t.Fatalf("Compact = %d, len %d; want 2, 3", n, pq.Len())
+	}
+	mustValidate(t, pq)
+
+	// Items a LazyIntQueue discarded, by popping past them or compacting,
+	// are live again once pushed elsewhere.
+	for _, compact := range []bool{false, true} {
+		l := NewLazyIntQueue(0)
+		cancelled := &Item{value: 1, priority: 9}
+		l.PushItem(cancelled)
+		l.PushItem(&Item{value: 2, priority: 5})
+		l.Cancel(cancelled)
+		if compact {
+			l.Compact()
+		} else {
+			l.PopItem()
+		}
+		pq := NewIntQueue(0)
+		heap.Push(&pq, cancelled)
+		if n := pq.Compact(); n != 0 || pq.Len() != 1 {
+			t.Fatalf("compact %v: Compact dropped %d live items", compact, n)
+		}
+	}
+}
+
+func TestSplitByPriority(t *testing.T) {
+	pq := newQueue(5, 9, 7, 1, 3)
+	tests := []struct {
+		pivot     int
+		high, low []int
+	}{
+		{5, []int{9, 7, 5}, []int{3, 1}},
+		{100, nil, []int{9, 7, 5, 3, 1}},
+		{-100, []int{9, 7, 5, 3, 1}, nil},
+		{1, []int{9, 7, 5, 3, 1}, nil},
+	}
+	for _, tt := range tests {
+		high, low := pq.SplitByPriority(tt.pivot)
+		mustValidate(t, high)
+		mustValidate(t, low)
+		if got := popPriorities(&high); !slices.Equal(got, tt.high) {
+			t.Errorf("pivot %d: high %v, want %v", tt.pivot, got, tt.high)
+		}
+		if got := popPriorities(&low); !slices.Equal(got, tt.low) {
+			t.Errorf("pivot %d: low %v, want %v", tt.pivot, got, tt.low)
+		}
+		// pq is left intact, Items and all.
+		mustValidate(t, pq)
+		if pq.Len() != 5 {
+			t.Fatalf("pq has %d items after splitting", pq.Len())
+		}
+	}
+	high, low := IntQueue{}.SplitByPriority(0)
+	if high.Len()+low.Len() != 0 {
+		t.Fatal("splitting an empty queue produced items")
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import "container/heap"
+
+// A LazyIntQueue is an IntQueue whose items can be cancelled in O(1). A
+// cancelled item is only marked; it stays in the heap until it reaches the
+// top, where popping discards it. When more than half of the queue is
+// cancelled items, the queue is compacted in O(n) to bound the memory they
+// hold.
+type LazyIntQueue struct {
+	q       IntQueue
+	deleted int // The number of cancelled items still in q.
+}
+
+func NewLazyIntQueue(n int) *LazyIntQueue {
+	return &LazyIntQueue{q: NewIntQueue(n)}
+}
+
+// Len returns the number of items in the queue, including cancelled ones.
+func (l *LazyIntQueue) Len() int { return len(l.q) }
+
+// LiveLen returns the number of items in the queue that are not cancelled.
+func (l *LazyIntQueue) LiveLen() int { return len(l.q) - l.deleted }
+
+// PushItem adds item to the queue.
+func (l *LazyIntQueue) PushItem(item *Item) {
+	item.deleted = false
+	heap.Push(&l.q, item)
+}
+
+// Cancel marks item, which must have been pushed to this queue, as deleted.
+// It reports false if item is no longer queued or was already cancelled.
+func (l *LazyIntQueue) Cancel(item *Item) bool {
+	if item.index < 0 || item.deleted {
+		return false
+	}
+	item.deleted = true
+	l.deleted++
+	if l.deleted > len(l.q)/2 {
+		l.Compact()
+	}
+	return true
+}
+
+// Compact drops every cancelled item from the queue now, rather than waiting
+// for them to reach the top, and returns how many were dropped.
+func (l *LazyIntQueue) Compact() int {
+	l.deleted = 0
+	return l.q.Compact()
+}
+
+// PopItem removes and returns the highest-priority item that is not
+// cancelled, discarding any cancelled items above it. It reports false if no
+// such item is left.
+func (l *LazyIntQueue) PopItem() (*Item, bool) {
+	l.skipDeleted()
+	return l.q.TryPop()
+}
+
+// Peek returns the highest-priority item that is not cancelled without
+// removing it, or reports false if there is none. Cancelled items above it
+// are discarded.
+func (l *LazyIntQueue) Peek() (*Item, bool) {
+	l.skipDeleted()
+	return l.q.PeekOK()
+}
+
+func (l *LazyIntQueue) skipDeleted() {
+	for len(l.q) > 0 && l.q[0].deleted {
+		// Clear the mark, so the item is not dropped if it is queued again.
+		heap.Pop(&l.q).(*Item).deleted = false
+		l.deleted--
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import "math/bits"
+
+// A MinMaxIntQueue holds Items in a min-max heap, which gives O(log n) access
+// to both the lowest and the highest priority. The tree levels alternate:
+// an item on an even level (the root is level 0) has the lowest priority of
+// its subtree, an item on an odd level the highest.
+type MinMaxIntQueue []*Item
+
+func NewMinMaxIntQueue(n int) MinMaxIntQueue {
+	return make(MinMaxIntQueue, 0, n)
+}
+
+func (pq MinMaxIntQueue) Len() int { return len(pq) }
+
+// PushItem adds item to the queue.
+func (pq *MinMaxIntQueue) PushItem(item *Item) {
+	item.index = len(*pq)
+	*pq = append(*pq, item)
+	pq.up(item.index)
+}
+
+// PeekMin returns the lowest-priority item, or reports false if the queue is
+// empty.
+func (pq MinMaxIntQueue) PeekMin() (*Item, bool) {
+	if len(pq) == 0 {
+		return nil, false
+	}
+	return pq[0], true
+}
+
+// PeekMax returns the highest-priority item, or reports false if the queue
+// is empty.
+func (pq MinMaxIntQueue) PeekMax() (*Item, bool) {
+	if len(pq) == 0 {
+		return nil, false
+	}
+	return pq[pq.maxIndex()], true
+}
+
+// PopMin removes and returns the lowest-priority item, or reports false if
+// the queue is empty.
+func (pq *MinMaxIntQueue) PopMin() (*Item, bool) {
+	if len(*pq) == 0 {
+		return nil, false
+	}
+	return pq.removeAt(0), true
+}
+
+// PopMax removes and returns the highest-priority item, or reports false if
+// the queue is empty.
+func (pq *MinMaxIntQueue) PopMax() (*Item, bool) {
+	if len(*pq) == 0 {
+		return nil, false
+	}
+	return pq.removeAt(pq.maxIndex()), true
+}
+
+// Remove removes item, which must be in the queue, and returns it.
+func (pq *MinMaxIntQueue) Remove(item *Item) *Item {
+	if item.index < 0 || item.index >= len(*pq) || (*pq)[item.index] != item {
+		panic("heap: item is not in the queue")
+	}
+	return pq.removeAt(item.index)
+}
+
+// maxIndex returns the index of the highest priority, which is the root of a
+// one-item queue and otherwise one of the root's children.
+func (pq MinMaxIntQueue) maxIndex() int {
+	switch {
+	case len(pq) == 1:
+		return 0
+	case len(pq) == 2 || pq[1].priority >= pq[2].priority:
+		return 1
+	}
+	return 2
+}
+
+func (pq *MinMaxIntQueue) removeAt(i int) *Item {
+	a := *pq
+	n := len(a) - 1
+	item := a[i]
+	if i != n {
+		a.swap(i, n)
+	}
+	a[n] = nil
+	a = a[:n]
+	*pq = a
+	if i != n {
+		// The item moved into i came from the last leaf, so it may belong
+		// above or below i.
+		moved := a[i]
+		a.down(i)
+		a.up(moved.index)
+	}
+	item.index = -1 // for safety
+	return item
+}
+
+func isMinLevel(i int) bool { return bits.Len(uint(i+1))%2 == 1 }
+
+// before reports whether item i belongs above item j on a min level, or on a
+// max level if min is false.
+func (pq MinMaxIntQueue) before(i, j int, min bool) bool {
+	if min {
+		return pq[i].priority < pq[j].priority
+	}
+	return pq[i].priority > pq[j].priority
+}
+
+func (pq MinMaxIntQueue) swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+	pq[i].index = i
+	pq[j].index = j
+}
+
+func (pq MinMaxIntQueue) up(i int) {
+	if i == 0 {
+		return
+	}
+	p := (i - 1) / 2
+	min := isMinLevel(i)
+	if pq.before(i, p, !min) {
+		// i belongs on the other kind of level, above its parent.
+		pq.swap(i, p)
+		i, min = p, !min
+	}
+	// Climb through grandparents, which are on the same kind of level.
+	for i > 2 {
+		g := ((i-1)/2 - 1) / 2
+		if !pq.before(i, g, min) {
+			break
+		}
+		pq.swap(i, g)
+		i = g
+	}
+}
+
+func (pq MinMaxIntQueue) down(i int) {
+	min := isMinLevel(i)
+	n := len(pq)
+	for {
+		c := 2*i + 1
+		if c >= n {
+			return
+		}
+		// Find the most extreme of i's children and grandchildren.
+		m := c
+		for _, j := range [...]int{c + 1, 2*c + 1, 2*c + 2, 2*c + 3, 2*c + 4} {
+			if j < n && pq.before(j, m, min) {
+				m = j
+			}
+		}
+		if !pq.before(m, i, min) {
+			return
+		}
+		pq.swap(m, i)
+		if m <= c+1 {
+			return // m was a child, which has no grandchildren of i below it.
+		}
+		if p := (m - 1) / 2; pq.before(p, m, min) {
+			pq.swap(m, p)
+		}
+		i = m
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// checkMinMaxHeap fails unless every item's index is its position and every
+// item lies within the bounds set by its ancestors.
+func checkMinMaxHeap(t *testing.T, pq MinMaxIntQueue) {
+	t.Helper()
+	for i, item := range pq {
+		if item.index != i {
+			t.Fatalf("item at %d records index %d", i, item.index)
+		}
+		for a := i; a > 0; {
+			a = (a - 1) / 2
+			if isMinLevel(a) && pq[a].priority > item.priority || !isMinLevel(a) && pq[a].priority < item.priority {
+				t.Fatalf("item at %d out of order with its ancestor at %d", i, a)
+			}
+		}
+	}
+}
+
+func TestMinMaxIntQueueRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 200; round++ {
+		var pq MinMaxIntQueue
+		var ref []*Item // Kept sorted by priority.
+		byPriority := func(a, b *Item) int { return a.priority - b.priority }
+		drop := func(item *Item) {
+			i := slices.Index(ref, item)
+			if i < 0 {
+				t.Fatalf("returned %v, which is not queued", item)
+			}
+			ref = slices.Delete(ref, i, i+1)
+		}
+		for step := 0; step < 200; step++ {
+			switch op := r.Intn(10); {
+			case op < 5:
+				item := &Item{value: step, priority: r.Intn(30)}
+				pq.PushItem(item)
+				ref = append(ref, item)
+				slices.SortStableFunc(ref, byPriority)
+			case op < 7:
+				item, ok := pq.PopMin()
+				if ok != (len(ref) > 0) {
+					t.Fatalf("PopMin ok = %v with %d queued", ok, len(ref))
+				}
+				if ok {
+					if item.priority != ref[0].priority {
+						t.Fatalf("PopMin = %d, want %d", item.priority, ref[0].priority)
+					}
+					drop(item)
+				}
+			case op < 9:
+				item, ok := pq.PopMax()
+				if ok != (len(ref) > 0) {
+					t.Fatalf("PopMax ok = %v with %d queued", ok, len(ref))
+				}
+				if ok {
+					if want := ref[len(ref)-1].priority; item.priority != want {
+						t.Fatalf("PopMax = %d, want %d", item.priority, want)
+					}
+					drop(item)
+				}
+			default:
+				if len(ref) > 0 {
+					item := ref[r.Intn(len(ref))]
+					if pq.Remove(item) != item || item.index != -1 {
+						t.Fatalf("Remove(%v) failed", item)
+					}
+					drop(item)
+				}
+			}
+			checkMinMaxHeap(t, pq)
+			if pq.Len() != len(ref) {
+				t.Fatalf("Len() = %d, want %d", pq.Len(), len(ref))
+			}
+			lo, okLo := pq.PeekMin()
+			hi, okHi := pq.PeekMax()
+			if okLo != (len(ref) > 0) || okHi != okLo {
+				t.Fatalf("Peek ok = %v, %v with %d queued", okLo, okHi, len(ref))
+			}
+			if okLo && (lo.priority != ref[0].priority || hi.priority != ref[len(ref)-1].priority) {
+				t.Fatalf("ends %d, %d, want %d, %d", lo.priority, hi.priority, ref[0].priority, ref[len(ref)-1].priority)
+			}
+		}
+	}
+}
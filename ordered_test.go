@@ -0,0 +1,136 @@
+package main
+
+import (
+	"container/heap"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestPushPopNKeepsTopK(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	q := NewIntQueueMin(0)
+	var all []int
+	for i := 0; i < 5; i++ {
+		p := r.Intn(1000)
+		heap.Push(q, &Item{priority: p})
+		all = append(all, p)
+	}
+	for batch := 0; batch < 50; batch++ {
+		incoming := make([]*Item, r.Intn(10))
+		for i := range incoming {
+			incoming[i] = &Item{priority: r.Intn(1000)}
+			all = append(all, incoming[i].priority)
+		}
+		if out := q.PushPopN(incoming); len(out) != len(incoming) {
+			t.Fatalf("PushPopN returned %d items for %d", len(out), len(incoming))
+		}
+		if q.Len() != 5 {
+			t.Fatalf("Len() = %d, want 5", q.Len())
+		}
+		var kept []int
+		for i, item := range q.items {
+			if item.index != i || i > 0 && q.items[(i-1)/2].priority > item.priority {
+				t.Fatalf("min-heap violated at %d", i)
+			}
+			kept = append(kept, item.priority)
+		}
+		slices.Sort(kept)
+		slices.Sort(all)
+		if want := all[len(all)-5:]; !slices.Equal(kept, want) {
+			t.Fatalf("kept %v, want %v", kept, want)
+		}
+	}
+}
+
+// benchTopK returns a min-queue of 1000 items and a batch of 100 to feed
+// through it.
+func benchTopK(r *rand.Rand) (*OrderedIntQueue, []*Item) {
+	q := NewIntQueueMin(1100)
+	for i := 0; i < 1000; i++ {
+		heap.Push(q, &Item{priority: r.Intn(1 << 20)})
+	}
+	batch := make([]*Item, 100)
+	for i := range batch {
+		batch[i] = &Item{priority: r.Intn(1 << 20)}
+	}
+	return q, batch
+}
+
+func BenchmarkPushPopN(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	q, batch := benchTopK(r)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		batch = q.PushPopN(batch)
+		for _, item := range batch {
+			item.priority = r.Intn(1 << 20)
+		}
+	}
+}
+
+func BenchmarkPushPopNNaive(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	q, batch := benchTopK(r)
+	out := make([]*Item, 0, len(batch))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out = out[:0]
+		for _, item := range batch {
+			heap.Push(q, item)
+			out = append(out, heap.Pop(q).(*Item))
+		}
+		batch, out = out, batch
+		for _, item := range batch {
+			item.priority = r.Intn(1 << 20)
+		}
+	}
+}
+
+func TestGrowthFunc(t *testing.T) {
+	q := NewIntQueueFunc(4, nil)
+	var calls []int
+	q.GrowthFunc = func(oldCap int) int {
+		calls = append(calls, oldCap)
+		return oldCap + oldCap/2
+	}
+	for i := 0; i < 10; i++ {
+		heap.Push(q, &Item{priority: i})
+	}
+	// 4 -> 6 -> 9 -> 13.
+	if !slices.Equal(calls, []int{4, 6, 9}) || cap(q.items) != 13 {
+		t.Fatalf("GrowthFunc called with %v, capacity %d", calls, cap(q.items))
+	}
+	mustValidate(t, q.items)
+}
+
+func TestGrowthFuncTooSmall(t *testing.T) {
+	q := NewIntQueueFunc(1, nil)
+	q.GrowthFunc = func(oldCap int) int { return 0 }
+	for i := 0; i < 3; i++ {
+		heap.Push(q, &Item{priority: i})
+	}
+	if q.Len() != 3 || cap(q.items) != 3 {
+		t.Fatalf("len %d, cap %d; want 3, 3", q.Len(), cap(q.items))
+	}
+}
+
+func TestNewIntQueueDeterministic(t *testing.T) {
+	pairs := [][2]int{{4, 1}, {2, 1}, {9, 3}, {1, 1}, {7, 3}, {3, 0}}
+	want := [][2]int{{7, 3}, {9, 3}, {1, 1}, {2, 1}, {4, 1}, {3, 0}}
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 20; trial++ {
+		q := NewIntQueueDeterministic(0)
+		for _, i := range r.Perm(len(pairs)) {
+			heap.Push(q, &Item{value: pairs[i][0], priority: pairs[i][1]})
+		}
+		var got [][2]int
+		for q.Len() > 0 {
+			item := heap.Pop(q).(*Item)
+			got = append(got, [2]int{item.value, item.priority})
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("push order %d popped %v, want %v", trial, got, want)
+		}
+	}
+}
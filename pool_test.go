@@ -0,0 +1,47 @@
+package main
+
+import (
+	"container/heap"
+	"testing"
+)
+
+func TestAcquireReleaseItem(t *testing.T) {
+	item := AcquireItem(1, 2)
+	if item.value != 1 || item.priority != 2 || item.index != -1 {
+		t.Fatalf("AcquireItem(1, 2) = %v with index %d", item, item.index)
+	}
+	item.Payload = "x"
+	ReleaseItem(item)
+	if item.Payload != nil || item.value != 0 || item.index != -1 {
+		t.Fatalf("released item not reset: %v", item)
+	}
+}
+
+// The benchmarks below push and pop through a queue of 1000 items, one
+// allocating each pushed Item and one recycling them through the pool.
+
+func BenchmarkPushPopNewItem(b *testing.B) {
+	b.ReportAllocs()
+	pq := NewIntQueue(1001)
+	for i := 0; i < 1000; i++ {
+		heap.Push(&pq, &Item{value: i, priority: i})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		heap.Push(&pq, &Item{value: i, priority: i % 1000})
+		heap.Pop(&pq)
+	}
+}
+
+func BenchmarkPushPopPooledItem(b *testing.B) {
+	b.ReportAllocs()
+	pq := NewIntQueue(1001)
+	for i := 0; i < 1000; i++ {
+		heap.Push(&pq, AcquireItem(i, i))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		heap.Push(&pq, AcquireItem(i, i%1000))
+		ReleaseItem(heap.Pop(&pq).(*Item))
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// KLargest returns the k highest-priority items, in decreasing priority
+// order, in O(n log k) time and O(k) extra space. It keeps the current top k
+// in a min-heap so the weakest of them is always at hand to be evicted. If k
+// exceeds len(items) every item is returned, sorted; if k <= 0 the result is
+// empty.
+//
+// The items go through a heap, so they should not belong to a live queue;
+// like popped items, those that were considered come out with an index of -1.
+func KLargest(items []*Item, k int) []*Item {
+	if k <= 0 {
+		return []*Item{}
+	}
+	q := NewIntQueueMin(min(k, len(items)))
+	for _, item := range items {
+		if q.Len() < k {
+			heap.Push(q, item)
+		} else if item.priority > q.items[0].priority {
+			q.items[0].index = -1 // for safety
+			q.items[0] = item
+			item.index = 0
+			heap.Fix(q, 0)
+		}
+	}
+	top := make([]*Item, q.Len())
+	for i := len(top) - 1; i >= 0; i-- {
+		top[i] = heap.Pop(q).(*Item)
+	}
+	return top
+}
+
+// HeapSortAsc sorts items in place by increasing priority using heapsort.
+// The sort is not stable: items of equal priority end up in no particular
+// order. The Items' index fields are not changed.
+func HeapSortAsc(items []*Item) {
+	v := itemView(items)
+	heap.Init(&v)
+	// Each Pop moves the current maximum to the end of the shrinking view,
+	// which leaves the backing array sorted.
+	for len(v) > 1 {
+		heap.Pop(&v)
+	}
+}
+
+// HeapSortDesc sorts items in place by decreasing priority. Like HeapSortAsc
+// it is not stable.
+func HeapSortDesc(items []*Item) {
+	HeapSortAsc(items)
+	slices.Reverse(items)
+}
+
+// MergeSorted merges streams, each already in decreasing priority order, into
+// one slice in decreasing priority order. Only the head of each stream is
+// kept in a heap, so for N items in k streams it costs O(N log k). Items of
+// equal priority from the same stream keep their relative order. The Items'
+// index fields are not changed.
+func MergeSorted(streams ...[]*Item) []*Item {
+	// A head tracks the next item of one stream.
+	type head struct{ stream, pos int }
+	n := 0
+	heads := NewPriorityQueue[head](len(streams))
+	for s, items := range streams {
+		n += len(items)
+		if len(items) > 0 {
+			heads = append(heads, &Element[head]{Value: head{s, 0}, Priority: items[0].priority, index: len(heads)})
+		}
+	}
+	heap.Init(&heads)
+	merged := make([]*Item, 0, n)
+	for len(heads) > 0 {
+		e := heads[0]
+		items := streams[e.Value.stream]
+		merged = append(merged, items[e.Value.pos])
+		if e.Value.pos++; e.Value.pos < len(items) {
+			e.Priority = items[e.Value.pos].priority
+			heap.Fix(&heads, 0)
+		} else {
+			heap.Pop(&heads)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,22 @@
+package main
+
+// QueueStats counts what has happened to a queue.
+type QueueStats struct {
+	Pushes uint64 // Items pushed.
+	Pops   uint64 // Items popped or removed.
+	MaxLen uint64 // The largest length the queue has reached.
+}
+
+func (s *QueueStats) pushed(n int) {
+	s.Pushes++
+	if uint64(n) > s.MaxLen {
+		s.MaxLen = uint64(n)
+	}
+}
+
+func (s *QueueStats) popped() { s.Pops++ }
+
+// reset zeroes the counters of a queue that currently holds n items.
+func (s *QueueStats) reset(n int) {
+	*s = QueueStats{MaxLen: uint64(n)}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// A SyncIntQueue is an IntQueue that is safe for concurrent use by multiple
+// goroutines. The zero value is an empty queue ready to use. Code that uses a
+// queue from a single goroutine should keep using IntQueue, which does no
+// locking.
+type SyncIntQueue struct {
+	mu sync.Mutex
+	q  IntQueue
+	// ready holds a token while there may be items for a waiting PopWait.
+	ready chan struct{}
+	stats QueueStats
+}
+
+func NewSyncIntQueue(n int) *SyncIntQueue {
+	return &SyncIntQueue{q: NewIntQueue(n)}
+}
+
+// Push adds item to the queue.
+func (s *SyncIntQueue) Push(item *Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	heap.Push(&s.q, item)
+	s.stats.pushed(len(s.q))
+	s.signal()
+}
+
+// Pop removes and returns the highest-priority item, or reports false if the
+// queue is empty.
+func (s *SyncIntQueue) Pop() (*Item, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tryPop()
+}
+
+// PopWait removes and returns the highest-priority item, waiting for one to
+// be pushed if the queue is empty. It returns ctx.Err() if ctx is done before
+// an item arrives.
+func (s *SyncIntQueue) PopWait(ctx context.Context) (*Item, error) {
+	for {
+		s.mu.Lock()
+		item, ok := s.tryPop()
+		if ok && len(s.q) > 0 {
+			// Pass the wakeup on so another waiter sees the remaining items.
+			s.signal()
+		}
+		ready := s.readyChan()
+		s.mu.Unlock()
+		if ok {
+			return item, nil
+		}
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Peek returns the highest-priority item without removing it, or reports
+// false if the queue is empty. Another goroutine may pop the item as soon as
+// Peek returns.
+func (s *SyncIntQueue) Peek() (*Item, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.q.PeekOK()
+}
+
+// Len returns the number of items in the queue.
+func (s *SyncIntQueue) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.q.Len()
+}
+
+// Stats returns the queue's usage counters.
+func (s *SyncIntQueue) Stats() QueueStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// ResetStats zeroes the queue's usage counters. MaxLen restarts from the
+// current length.
+func (s *SyncIntQueue) ResetStats() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.reset(len(s.q))
+}
+
+// tryPop is TryPop with the stats kept. s.mu must be held.
+func (s *SyncIntQueue) tryPop() (*Item, bool) {
+	item, ok := s.q.TryPop()
+	if ok {
+		s.stats.popped()
+	}
+	return item, ok
+}
+
+// signal wakes at most one goroutine waiting in PopWait. s.mu must be held.
+func (s *SyncIntQueue) signal() {
+	select {
+	case s.readyChan() <- struct{}{}:
+	default:
+		// A token is already waiting to be taken.
+	}
+}
+
+func (s *SyncIntQueue) readyChan() chan struct{} {
+	if s.ready == nil {
+		s.ready = make(chan struct{}, 1)
+	}
+	return s.ready
+}
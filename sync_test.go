@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSyncIntQueuePopWaitConsumers(t *testing.T) {
+	const consumers, producers, perProducer = 8, 4, 2500
+	s := NewSyncIntQueue(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	got := make(chan int, producers*perProducer)
+	errs := make(chan error, consumers)
+	var wg sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				item, err := s.PopWait(ctx)
+				if err != nil {
+					errs <- err
+					return
+				}
+				got <- item.value
+			}
+		}()
+	}
+	for p := 0; p < producers; p++ {
+		go func() {
+			for i := 0; i < perProducer; i++ {
+				s.Push(&Item{value: p*perProducer + i, priority: i})
+			}
+		}()
+	}
+
+	// Every item must be delivered exactly once; a lost wakeup shows up as
+	// a stall with items still queued.
+	seen := make(map[int]bool)
+	timeout := time.After(10 * time.Second)
+	for len(seen) < producers*perProducer {
+		select {
+		case v := <-got:
+			if seen[v] {
+				t.Fatalf("value %d delivered twice", v)
+			}
+			seen[v] = true
+		case <-timeout:
+			t.Fatalf("stalled after %d items with %d queued", len(seen), s.Len())
+		}
+	}
+
+	cancel()
+	done := make(chan struct{})
+	go func() { wg.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("consumers did not return after cancellation")
+	}
+	close(errs)
+	for err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("PopWait returned %v, want context.Canceled", err)
+		}
+	}
+	if s.Len() != 0 {
+		t.Errorf("Len() = %d after draining", s.Len())
+	}
+}
+
+func TestSyncIntQueuePopWaitQueuedItem(t *testing.T) {
+	s := NewSyncIntQueue(0)
+	s.Push(&Item{value: 7})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// An item already queued is returned even if ctx is done.
+	if item, err := s.PopWait(ctx); err != nil || item.value != 7 {
+		t.Fatalf("PopWait = %v, %v", item, err)
+	}
+	if _, err := s.PopWait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("PopWait on an empty queue returned %v", err)
+	}
+}
+
+func TestSyncIntQueuePushPanicReleasesLock(t *testing.T) {
+	s := NewSyncIntQueue(0)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("pushing a nil item did not panic")
+			}
+		}()
+		s.Push(nil)
+	}()
+	done := make(chan struct{})
+	go func() {
+		s.Push(&Item{value: 1})
+		s.Len()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("queue stayed locked after a panicking Push")
+	}
+}
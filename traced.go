@@ -0,0 +1,90 @@
+package main
+
+import "container/heap"
+
+// A TracedIntQueue is an IntQueue that can report every step its sifts take,
+// which is useful for learning how a heap works or for asserting on its
+// behavior in tests. container/heap does not expose its sifts, so when Trace
+// is set the queue sifts with its own copy of them, making the same
+// comparisons and swaps in the same order. When Trace is nil it simply uses
+// container/heap.
+type TracedIntQueue struct {
+	q IntQueue
+
+	// Trace, if non-nil, is called with op "less" before each comparison of
+	// the items at i and j, and with op "swap" before each swap of them.
+	Trace func(op string, i, j int)
+}
+
+func NewTracedIntQueue(n int) *TracedIntQueue {
+	return &TracedIntQueue{q: NewIntQueue(n)}
+}
+
+func (t *TracedIntQueue) Len() int { return t.q.Len() }
+
+// PushItem adds item to the queue.
+func (t *TracedIntQueue) PushItem(item *Item) {
+	if t.Trace == nil {
+		heap.Push(&t.q, item)
+		return
+	}
+	t.q.Push(item)
+	t.up(len(t.q) - 1)
+}
+
+// PopItem removes and returns the highest-priority item, or reports false if
+// the queue is empty.
+func (t *TracedIntQueue) PopItem() (*Item, bool) {
+	if t.Trace == nil {
+		return t.q.TryPop()
+	}
+	n := len(t.q) - 1
+	if n < 0 {
+		return nil, false
+	}
+	t.swap(0, n)
+	t.down(0, n)
+	return t.q.Pop().(*Item), true
+}
+
+// Peek returns the highest-priority item without removing it, or reports
+// false if the queue is empty.
+func (t *TracedIntQueue) Peek() (*Item, bool) { return t.q.PeekOK() }
+
+func (t *TracedIntQueue) less(i, j int) bool {
+	t.Trace("less", i, j)
+	return t.q.Less(i, j)
+}
+
+func (t *TracedIntQueue) swap(i, j int) {
+	t.Trace("swap", i, j)
+	t.q.Swap(i, j)
+}
+
+func (t *TracedIntQueue) up(j int) {
+	for j > 0 {
+		i := (j - 1) / 2 // parent
+		if !t.less(j, i) {
+			break
+		}
+		t.swap(i, j)
+		j = i
+	}
+}
+
+func (t *TracedIntQueue) down(i, n int) {
+	for {
+		j := 2*i + 1
+		if j >= n {
+			return
+		}
+		if r := j + 1; r < n && t.less(r, j) {
+			j = r // right child
+		}
+		if !t.less(j, i) {
+			return
+		}
+		t.swap(i, j)
+		i = j
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import "container/heap"
+
+// A ValueIntQueue implements heap.Interface and holds items by value rather
+// than by pointer, ordered like IntQueue. Items are not allocated one by one,
+// but an item moves whenever the heap is reordered, so there is no stable
+// *Item to hold on to across operations.
+//
+// Only each item's value, priority and index are stored, in 24 bytes, not
+// the whole Item: an Item carries a Payload, which is a pointer, and fields
+// used by other queues. The array therefore holds no pointers at all and the
+// garbage collector never scans it, but a Payload pushed with an Item is
+// dropped.
+//
+// heap.Push and heap.Pop box each item in an interface, which allocates;
+// PushItem and PopItem avoid that and do not allocate once the backing array
+// is large enough, where IntQueue allocates every Item it holds. In
+// BenchmarkValueIntQueue a push and a pop allocated nothing and took about a
+// third of the time they took on IntQueue, which allocated a 64-byte Item
+// each time.
+type ValueIntQueue []valueItem
+
+// A valueItem is the part of an Item that a ValueIntQueue keeps.
+type valueItem struct {
+	value    int
+	priority int
+	index    int
+}
+
+func (v valueItem) item() Item {
+	return Item{value: v.value, priority: v.priority, index: v.index}
+}
+
+func NewValueIntQueue(n int) ValueIntQueue {
+	return make(ValueIntQueue, 0, n)
+}
+
+func (pq ValueIntQueue) Len() int { return len(pq) }
+
+func (pq ValueIntQueue) Less(i, j int) bool {
+	return pq[i].priority > pq[j].priority
+}
+
+func (pq ValueIntQueue) Swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+	pq[i].index = i
+	pq[j].index = j
+}
+
+// Push adds x, which must be an Item. Only its value and priority are kept.
+func (pq *ValueIntQueue) Push(x interface{}) {
+	item := x.(Item)
+	*pq = append(*pq, valueItem{value: item.value, priority: item.priority, index: len(*pq)})
+}
+
+// Pop removes the last item and returns it as an Item.
+func (pq *ValueIntQueue) Pop() interface{} {
+	a := *pq
+	n := len(a)
+	item := a[n-1].item()
+	item.index = -1 // for safety
+	*pq = a[0 : n-1]
+	return item
+}
+
+// PushItem adds an item with the given value and priority.
+func (pq *ValueIntQueue) PushItem(value, priority int) {
+	n := len(*pq)
+	*pq = append(*pq, valueItem{value: value, priority: priority, index: n})
+	// Fix sifts without going through Push, so nothing is boxed.
+	heap.Fix(pq, n)
+}
+
+// PopItem removes and returns the highest-priority item, or reports false if
+// the queue is empty.
+func (pq *ValueIntQueue) PopItem() (Item, bool) {
+	a := *pq
+	n := len(a) - 1
+	if n < 0 {
+		return Item{}, false
+	}
+	a.Swap(0, n)
+	item := a[n].item()
+	item.index = -1 // for safety
+	*pq = a[:n]
+	if n > 0 {
+		heap.Fix(pq, 0)
+	}
+	return item, true
+}
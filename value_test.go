@@ -0,0 +1,58 @@
+package main
+
+import (
+	"container/heap"
+	"slices"
+	"testing"
+)
+
+func TestValueIntQueue(t *testing.T) {
+	pq := NewValueIntQueue(0)
+	for i, p := range []int{5, 9, 7, 1} {
+		pq.PushItem(i, p)
+	}
+	heap.Push(&pq, Item{value: 4, priority: 8, Payload: "dropped"})
+	var got []int
+	for {
+		item, ok := pq.PopItem()
+		if !ok {
+			break
+		}
+		if item.index != -1 || item.Payload != nil {
+			t.Fatalf("popped %v with index %d, payload %v", item, item.index, item.Payload)
+		}
+		got = append(got, item.value)
+	}
+	if !slices.Equal(got, []int{1, 4, 2, 0, 3}) {
+		t.Fatalf("popped values %v", got)
+	}
+}
+
+// The benchmarks below push and pop through a queue of 1000 items, reporting
+// allocations; the pointer queue allocates a new Item per push.
+
+func BenchmarkValueIntQueue(b *testing.B) {
+	b.ReportAllocs()
+	pq := NewValueIntQueue(1001)
+	for i := 0; i < 1000; i++ {
+		pq.PushItem(i, i*7919%1000)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pq.PushItem(i, i*7919%1000)
+		pq.PopItem()
+	}
+}
+
+func BenchmarkValueIntQueuePointers(b *testing.B) {
+	b.ReportAllocs()
+	pq := NewIntQueue(1001)
+	for i := 0; i < 1000; i++ {
+		heap.Push(&pq, &Item{value: i, priority: i * 7919 % 1000})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		heap.Push(&pq, &Item{value: i, priority: i * 7919 % 1000})
+		heap.Pop(&pq)
+	}
+}